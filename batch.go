@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -14,6 +15,11 @@ type QueuedQuery struct {
 	arguments []any
 	fn        batchItemFunc
 	sd        *pgconn.StatementDescription
+
+	inferTypes bool      // send without parameter OIDs instead of describing the statement first
+	copyFrom   *copyFrom // copy data source when queued with QueueCopyFrom
+
+	commandTag pgconn.CommandTag
 }
 
 type batchItemFunc func(br BatchResults) error
@@ -45,6 +51,35 @@ func (qq *QueuedQuery) QueryRow(fn func(row Row) error) {
 	}
 }
 
+// describeSQL returns the SQL that must be described to send qq. For a copy this describes the copied columns.
+func (qq *QueuedQuery) describeSQL() string {
+	if qq.copyFrom != nil {
+		describeSQL, _ := copyFromSQL(qq.copyFrom.tableName, qq.copyFrom.columnNames)
+		return describeSQL
+	}
+	return qq.query
+}
+
+// ForEachRow sets fn to be called for each row of the response to qq after the row is scanned into scans. See
+// ForEachRow for details.
+func (qq *QueuedQuery) ForEachRow(scans []any, fn func() error) {
+	qq.fn = func(br BatchResults) error {
+		rows, err := br.Query()
+		if err != nil {
+			return err
+		}
+
+		qq.commandTag, err = ForEachRow(rows, scans, fn)
+		return err
+	}
+}
+
+// CommandTag returns the command tag of the response to qq when fn was set with ForEachRow. It is only available after
+// all rows have been read.
+func (qq *QueuedQuery) CommandTag() pgconn.CommandTag {
+	return qq.commandTag
+}
+
 // Exec sets fn to be called when the response to qq is received.
 func (qq *QueuedQuery) Exec(fn func(ct pgconn.CommandTag) error) {
 	qq.fn = func(br BatchResults) error {
@@ -58,12 +93,48 @@ func (qq *QueuedQuery) Exec(fn func(ct pgconn.CommandTag) error) {
 }
 
 // Batch queries are a way of bundling multiple queries together to avoid
-// unnecessary network round trips. A Batch must only be sent once.
+// unnecessary network round trips. A Batch must only be sent once unless it is
+// Reset.
+//
+// A Batch is not wrapped in BEGIN and COMMIT. Instead, the server runs all
+// queued queries in a single implicit transaction. If any query fails, the
+// remaining queries are not executed and the effects of the preceding queries
+// are rolled back. Queue explicit transaction control statements, send the
+// batch on a Tx, or set IsolateErrors to change this behavior.
+//
+// With QueryExecModeCacheStatement (the default) each distinct query is parsed
+// once as a cached prepared statement, so queuing the same query many times
+// only sends its parameters for each item. The other modes send the query text
+// for every item.
 type Batch struct {
 	queuedQueries []*QueuedQuery
+
+	// IsolateErrors causes an error executing one query to only fail that query. The remaining queries still execute
+	// and the error is returned when the results of the failed query are read. Outside of a transaction each query is
+	// run in its own implicit transaction. Inside of a transaction each query is preceded by a savepoint that is rolled
+	// back to if the query fails. This requires an additional synchronization point per query and, in a transaction,
+	// additional statements per query. Errors preparing the queries, such as syntax errors, still fail the entire
+	// batch. IsolateErrors requires a QueryExecMode that uses pipeline mode (the default) and cannot be used with
+	// QueryExecModeExec or QueryExecModeSimpleProtocol.
+	IsolateErrors bool
+
+	// MaxBufferedBytes limits the memory used to send a large batch. When it is greater than zero, the queued queries
+	// are written to the server whenever approximately MaxBufferedBytes of query text, arguments, and copy data are
+	// pending instead of all at once when the batch is sent. This does not add synchronization points: the queries still
+	// run in the same implicit transaction and their results are read in the same way. MaxBufferedBytes only applies to
+	// QueryExecModes that use pipeline mode (the default). Zero means no limit.
+	MaxBufferedBytes int
+
+	results interface{ isClosed() bool } // results of the most recent send
 }
 
-// Queue queues a query to batch b. query can be an SQL query or the name of a prepared statement.
+// Queue queues a query to batch b. query can be an SQL query or the name of a prepared statement. As with Conn.Query,
+// the format of each result column is chosen from the connection's type map when the query is described: binary for
+// types with a codec that supports it and text otherwise. When the query is not described the results are in the text
+// format.
+//
+// Each query must be a single statement. SendBatch fails without sending anything if a query contains multiple
+// statements separated by semicolons, as each query must have exactly one result.
 func (b *Batch) Queue(query string, arguments ...any) *QueuedQuery {
 	qq := &QueuedQuery{
 		query:     query,
@@ -73,11 +144,102 @@ func (b *Batch) Queue(query string, arguments ...any) *QueuedQuery {
 	return qq
 }
 
+// QueueSimple queues a query to batch b without describing it first. Unless query is the name of a prepared statement,
+// the parameter OIDs are sent as zero (unknown) and the server infers the parameter types from the query, like
+// QueryExecModeExec. arguments are encoded in the text format based on their Go types and results are returned in the
+// text format. This avoids the round trip Queue may use to describe a query that is not yet cached, but an argument is
+// only valid if the server can convert its text representation to the inferred type.
+func (b *Batch) QueueSimple(query string, arguments ...any) *QueuedQuery {
+	qq := b.Queue(query, arguments...)
+	qq.inferTypes = true
+	return qq
+}
+
+// QueueCopyFrom queues a copy of the rows of rowSrc into tableName to batch b. It is the batch version of
+// Conn.CopyFrom and has the same requirements. The COPY is sent inline with the other queued queries so, for example,
+// a truncate, a copy, and an index rebuild can be sent in a single round trip. Its result is read with
+// BatchResults.Exec and the number of rows copied is the RowsAffected of the command tag.
+//
+// All rows of rowSrc are read and encoded in memory when the batch is sent. The types of the columns are described
+// along with any other queued queries that need to be described, so tableName must exist before the batch is sent; it
+// cannot be created by a preceding query in the same batch. QueueCopyFrom requires a QueryExecMode that uses pipeline
+// mode (the default) and cannot be used with QueryExecModeExec or QueryExecModeSimpleProtocol.
+func (b *Batch) QueueCopyFrom(tableName Identifier, columnNames []string, rowSrc CopyFromSource) *QueuedQuery {
+	_, copySQL := copyFromSQL(tableName, columnNames)
+	qq := b.Queue(copySQL)
+	qq.copyFrom = &copyFrom{
+		tableName:   tableName,
+		columnNames: columnNames,
+		rowSrc:      rowSrc,
+	}
+	return qq
+}
+
+// QueueFunc queues a query to batch b and sets fn to be called for each row of the response after the row is scanned
+// into scans. It is shorthand for Queue followed by QueuedQuery.ForEachRow.
+func (b *Batch) QueueFunc(query string, arguments []any, scans []any, fn func() error) *QueuedQuery {
+	qq := b.Queue(query, arguments...)
+	qq.ForEachRow(scans, fn)
+	return qq
+}
+
 // Len returns number of queries that have been queued so far.
 func (b *Batch) Len() int {
 	return len(b.queuedQueries)
 }
 
+// Reset removes all queued queries so b can be queued and sent again. The memory used to hold the queued queries is
+// retained. Reset returns an error without modifying b if b was sent and its BatchResults have not been closed.
+func (b *Batch) Reset() error {
+	if b.results != nil && !b.results.isClosed() {
+		return errors.New("cannot reset batch before its results are closed")
+	}
+
+	for i := range b.queuedQueries {
+		b.queuedQueries[i] = nil
+	}
+	b.queuedQueries = b.queuedQueries[:0]
+	b.results = nil
+
+	return nil
+}
+
+// ItemFields returns the field descriptions of the query at index. The field descriptions are available after the
+// batch has been sent with QueryExecModeCacheStatement, QueryExecModeCacheDescribe, or QueryExecModeDescribeExec as
+// those modes describe every query before any results are read. ItemFields returns nil if the batch has not been
+// sent, was sent with another mode, if the query does not return rows, or if index is out of range.
+func (b *Batch) ItemFields(index int) []pgconn.FieldDescription {
+	if index < 0 || index >= len(b.queuedQueries) {
+		return nil
+	}
+
+	sd := b.queuedQueries[index].sd
+	if sd == nil {
+		return nil
+	}
+
+	return sd.Fields
+}
+
+// ItemSQL returns the SQL of the query at index as it was queued. For a query queued by the name of a prepared
+// statement it is the name. ItemSQL returns an empty string if index is out of range.
+func (b *Batch) ItemSQL(index int) string {
+	if index < 0 || index >= len(b.queuedQueries) {
+		return ""
+	}
+
+	return b.queuedQueries[index].query
+}
+
+// ItemArguments returns the arguments of the query at index. ItemArguments returns nil if index is out of range.
+func (b *Batch) ItemArguments(index int) []any {
+	if index < 0 || index >= len(b.queuedQueries) {
+		return nil
+	}
+
+	return b.queuedQueries[index].arguments
+}
+
 type BatchResults interface {
 	// Exec reads the results from the next query in the batch as if the query has been sent with Conn.Exec. Prefer
 	// calling Exec on the QueuedQuery.
@@ -85,10 +247,15 @@ type BatchResults interface {
 
 	// Query reads the results from the next query in the batch as if the query has been sent with Conn.Query. Prefer
 	// calling Query on the QueuedQuery.
+	//
+	// Rows are read from the connection as Rows.Next is called. They are not buffered so a query that returns a very
+	// large number of rows can be iterated in constant memory. The results of the queries that follow are not read until
+	// the returned Rows is closed.
 	Query() (Rows, error)
 
 	// QueryRow reads the results from the next query in the batch as if the query has been sent with Conn.QueryRow.
-	// Prefer calling QueryRow on the QueuedQuery.
+	// Prefer calling QueryRow on the QueuedQuery. Any error reading the results is returned by Row.Scan. Use
+	// BatchQueryRow to get the error immediately.
 	QueryRow() Row
 
 	// Close closes the batch operation. All unread results are read and any callback functions registered with
@@ -97,13 +264,163 @@ type BatchResults interface {
 	//
 	// Close must be called before the underlying connection can be used again. Any error that occurred during a batch
 	// operation may have made it impossible to resyncronize the connection with the server. In this case the underlying
-	// connection will have been closed.
+	// connection will have been closed. In particular, if the context passed to SendBatch is done before all results
+	// are read the connection is closed rather than left with unread results, so a pool will not reuse it.
 	//
 	// Close is safe to call multiple times. If it returns an error subsequent calls will return the same error. Callback
 	// functions will not be rerun.
 	Close() error
 }
 
+// BatchQueryRow reads the results from the next query in br like BatchResults.QueryRow, but returns an error reading the
+// results immediately instead of deferring it to Row.Scan. This allows distinguishing a query that returned no rows
+// (Row.Scan returns ErrNoRows) from a batch that failed, e.g. because its context was canceled or the connection was
+// lost.
+func BatchQueryRow(br BatchResults) (Row, error) {
+	rows, err := br.Query()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	if rows, ok := rows.(*baseRows); ok {
+		return (*connRow)(rows), nil
+	}
+	return &rowsRow{rows: rows}, nil
+}
+
+// rowsRow implements Row for any Rows.
+type rowsRow struct {
+	rows Rows
+}
+
+func (r *rowsRow) Scan(dest ...any) error {
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if r.rows.Err() == nil {
+			return ErrNoRows
+		}
+		return r.rows.Err()
+	}
+
+	err := r.rows.Scan(dest...)
+	if err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+// BatchCollectRows reads the results from the next query in br with BatchResults.Query and collects them with
+// CollectRows. For example, the rows can be collected into a []T with RowToStructByName[T], which maps columns to
+// struct fields by name or db tag including fields of embedded structs, or into a []map[string]any with RowToMap.
+func BatchCollectRows[T any](br BatchResults, fn RowToFunc[T]) ([]T, error) {
+	rows, err := br.Query()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	return CollectRows(rows, fn)
+}
+
+// BatchItemError is returned when the server returns an error for a query in a batch. It identifies the query that
+// failed by its index in the batch. Use errors.As to get the *pgconn.PgError returned by the server.
+//
+// This is a breaking change from earlier versions that returned the *pgconn.PgError directly. A type assertion such as
+// err.(*pgconn.PgError) no longer matches an error returned by BatchResults and must be replaced by errors.As.
+//
+// The error that fails a query is also returned when reading the results of the queries that follow it. It continues
+// to identify the query that failed.
+type BatchItemError struct {
+	// Index is the index of the query in the batch.
+	Index int
+
+	// SQL is the SQL of the query. If the query was queued by the name of a prepared statement it is the SQL of the
+	// prepared statement.
+	SQL string
+
+	// StatementName is the name of the prepared statement if the query was queued by name.
+	StatementName string
+
+	Err error
+}
+
+func (e *BatchItemError) Error() string {
+	if e.StatementName != "" {
+		return fmt.Sprintf("batch item %d (prepared statement %s, query: %s) failed: %v", e.Index, e.StatementName, e.SQL, e.Err)
+	}
+	return fmt.Sprintf("batch item %d (query: %s) failed: %v", e.Index, e.SQL, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// batchItemErrors wraps the errors returned by the server for the queries of a batch in *BatchItemError.
+type batchItemErrors struct {
+	last    *BatchItemError
+	lastErr error
+
+	// statementTimeoutDeadline is the context deadline statement_timeout was set from by TxOptions.DeadlineStatementTimeout.
+	// If it is set, a query canceled by the server or timed out by the context is reported as a
+	// *DeadlineStatementTimeoutError.
+	statementTimeoutDeadline time.Time
+}
+
+// wrap returns err wrapped in a *BatchItemError for the query at index in b if err was returned by the server or was
+// caused by the statement timeout deadline. If err is the error that failed a previous query it is still attributed to
+// that query.
+func (e *batchItemErrors) wrap(b *Batch, index int, err error) error {
+	if err == nil || b == nil || index < 0 || index >= len(b.queuedQueries) {
+		return err
+	}
+
+	_, isPgErr := err.(*pgconn.PgError)
+	isDeadlineErr := e.isDeadlineErr(err)
+	if !isPgErr && !isDeadlineErr {
+		return err
+	}
+
+	if e.last != nil && e.lastErr == err {
+		return e.last
+	}
+
+	bi := b.queuedQueries[index]
+	e.lastErr = err
+	e.last = &BatchItemError{Index: index, SQL: bi.query, Err: err}
+	if isDeadlineErr {
+		e.last.Err = &DeadlineStatementTimeoutError{Deadline: e.statementTimeoutDeadline, Err: err}
+	}
+	if bi.sd != nil && bi.sd.Name != "" && bi.sd.Name == bi.query {
+		e.last.SQL = bi.sd.SQL
+		e.last.StatementName = bi.sd.Name
+	}
+
+	return e.last
+}
+
+// isDeadlineErr returns true if err is the server canceling the query or the context deadline passing while
+// statement_timeout is set from the deadline.
+func (e *batchItemErrors) isDeadlineErr(err error) bool {
+	if e.statementTimeoutDeadline.IsZero() {
+		return false
+	}
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == "57014" // query_canceled
+	}
+	return pgconn.Timeout(err)
+}
+
+// previous returns the *BatchItemError for err if err already failed a query. Otherwise it returns err.
+func (e *batchItemErrors) previous(err error) error {
+	if err != nil && e.last != nil && e.lastErr == err {
+		return e.last
+	}
+	return err
+}
+
 type batchResults struct {
 	ctx       context.Context
 	conn      *Conn
@@ -113,6 +430,8 @@ type batchResults struct {
 	qqIdx     int
 	closed    bool
 	endTraced bool
+
+	itemErrs batchItemErrors
 }
 
 // Exec reads the results from the next query in the batch as if the query has been sent with Exec.
@@ -124,6 +443,7 @@ func (br *batchResults) Exec() (pgconn.CommandTag, error) {
 		return pgconn.CommandTag{}, fmt.Errorf("batch already closed")
 	}
 
+	index := br.qqIdx
 	query, arguments, _ := br.nextQueryAndArgs()
 
 	if !br.mrr.NextResult() {
@@ -131,6 +451,7 @@ func (br *batchResults) Exec() (pgconn.CommandTag, error) {
 		if err == nil {
 			err = errors.New("no result")
 		}
+		err = br.itemErrs.wrap(br.b, index, err)
 		if br.conn.batchTracer != nil {
 			br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
 				SQL:  query,
@@ -142,7 +463,7 @@ func (br *batchResults) Exec() (pgconn.CommandTag, error) {
 	}
 
 	commandTag, err := br.mrr.ResultReader().Close()
-	br.err = err
+	br.err = br.itemErrs.wrap(br.b, index, err)
 
 	if br.conn.batchTracer != nil {
 		br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
@@ -158,6 +479,7 @@ func (br *batchResults) Exec() (pgconn.CommandTag, error) {
 
 // Query reads the results from the next query in the batch as if the query has been sent with Query.
 func (br *batchResults) Query() (Rows, error) {
+	index := br.qqIdx
 	query, arguments, ok := br.nextQueryAndArgs()
 	if !ok {
 		query = "batch query"
@@ -174,12 +496,14 @@ func (br *batchResults) Query() (Rows, error) {
 
 	rows := br.conn.getRows(br.ctx, query, arguments)
 	rows.batchTracer = br.conn.batchTracer
+	rows.wrapBatchItemError = func(err error) error { return br.itemErrs.wrap(br.b, index, err) }
 
 	if !br.mrr.NextResult() {
 		rows.err = br.mrr.Close()
 		if rows.err == nil {
 			rows.err = errors.New("no result")
 		}
+		rows.err = rows.wrapBatchItemError(rows.err)
 		rows.closed = true
 
 		if br.conn.batchTracer != nil {
@@ -216,12 +540,8 @@ func (br *batchResults) Close() error {
 		}
 	}()
 
-	if br.err != nil {
-		return br.err
-	}
-
 	if br.closed {
-		return nil
+		return br.err
 	}
 
 	// Read and run fn for all remaining items
@@ -238,9 +558,15 @@ func (br *batchResults) Close() error {
 
 	br.closed = true
 
-	err := br.mrr.Close()
-	if br.err == nil {
-		br.err = err
+	// The remaining results are discarded even if an error occurred so the connection is not left busy. If the context
+	// is done this fails and closes the connection instead.
+	if br.mrr != nil {
+		err := br.mrr.Close()
+		if br.err == nil {
+			// The error that failed a query is returned again by the MultiResultReader. Any other error, such as a deferred
+			// constraint violation when the implicit transaction commits, does not belong to a query.
+			br.err = br.itemErrs.previous(err)
+		}
 	}
 
 	return br.err
@@ -250,6 +576,11 @@ func (br *batchResults) earlyError() error {
 	return br.err
 }
 
+// isClosed returns true if Close has been called. endTraced is set by the first call to Close.
+func (br *batchResults) isClosed() bool {
+	return br.endTraced
+}
+
 func (br *batchResults) nextQueryAndArgs() (query string, args []any, ok bool) {
 	if br.b != nil && br.qqIdx < len(br.b.queuedQueries) {
 		bi := br.b.queuedQueries[br.qqIdx]
@@ -271,6 +602,12 @@ type pipelineBatchResults struct {
 	qqIdx     int
 	closed    bool
 	endTraced bool
+
+	isolateErrors   bool // each query is followed by a sync so errors only fail that query
+	savepoints      bool // each query is preceded by a savepoint statement
+	itemSyncPending bool // the sync following the current query has not been read
+
+	itemErrs batchItemErrors
 }
 
 // Exec reads the results from the next query in the batch as if the query has been sent with Exec.
@@ -281,21 +618,36 @@ func (br *pipelineBatchResults) Exec() (pgconn.CommandTag, error) {
 	if br.closed {
 		return pgconn.CommandTag{}, fmt.Errorf("batch already closed")
 	}
-	if br.lastRows != nil && br.lastRows.err != nil {
+	if br.lastRows != nil && br.lastRows.err != nil && !br.isItemError(br.lastRows.err) {
 		return pgconn.CommandTag{}, br.err
 	}
 
+	if br.isolateErrors {
+		if err := br.beginItem(); err != nil {
+			br.err = err
+			return pgconn.CommandTag{}, err
+		}
+	}
+
+	index := br.qqIdx
 	query, arguments, _ := br.nextQueryAndArgs()
 
 	results, err := br.pipeline.GetResults()
 	if err != nil {
-		br.err = err
+		err = br.itemErrs.wrap(br.b, index, err)
+		if !br.isItemError(err) {
+			br.err = err
+		}
 		return pgconn.CommandTag{}, err
 	}
 	var commandTag pgconn.CommandTag
 	switch results := results.(type) {
 	case *pgconn.ResultReader:
-		commandTag, br.err = results.Close()
+		commandTag, err = results.Close()
+		err = br.itemErrs.wrap(br.b, index, err)
+		if !br.isItemError(err) {
+			br.err = err
+		}
 	default:
 		return pgconn.CommandTag{}, fmt.Errorf("unexpected pipeline result: %T", results)
 	}
@@ -305,7 +657,7 @@ func (br *pipelineBatchResults) Exec() (pgconn.CommandTag, error) {
 			SQL:        query,
 			Args:       arguments,
 			CommandTag: commandTag,
-			Err:        br.err,
+			Err:        err,
 		})
 	}
 
@@ -323,11 +675,19 @@ func (br *pipelineBatchResults) Query() (Rows, error) {
 		return &baseRows{err: alreadyClosedErr, closed: true}, alreadyClosedErr
 	}
 
-	if br.lastRows != nil && br.lastRows.err != nil {
+	if br.lastRows != nil && br.lastRows.err != nil && !br.isItemError(br.lastRows.err) {
 		br.err = br.lastRows.err
 		return &baseRows{err: br.err, closed: true}, br.err
 	}
 
+	if br.isolateErrors {
+		if err := br.beginItem(); err != nil {
+			br.err = err
+			return &baseRows{err: br.err, closed: true}, br.err
+		}
+	}
+
+	index := br.qqIdx
 	query, arguments, ok := br.nextQueryAndArgs()
 	if !ok {
 		query = "batch query"
@@ -335,11 +695,15 @@ func (br *pipelineBatchResults) Query() (Rows, error) {
 
 	rows := br.conn.getRows(br.ctx, query, arguments)
 	rows.batchTracer = br.conn.batchTracer
+	rows.wrapBatchItemError = func(err error) error { return br.itemErrs.wrap(br.b, index, err) }
 	br.lastRows = rows
 
 	results, err := br.pipeline.GetResults()
 	if err != nil {
-		br.err = err
+		err = rows.wrapBatchItemError(err)
+		if !br.isItemError(err) {
+			br.err = err
+		}
 		rows.err = err
 		rows.closed = true
 
@@ -384,41 +748,110 @@ func (br *pipelineBatchResults) Close() error {
 		}
 	}()
 
-	if br.err != nil {
-		return br.err
-	}
-
-	if br.lastRows != nil && br.lastRows.err != nil {
+	if br.err == nil && br.lastRows != nil && br.lastRows.err != nil && !br.isItemError(br.lastRows.err) {
 		br.err = br.lastRows.err
-		return br.err
 	}
 
 	if br.closed {
-		return nil
+		return br.err
 	}
 
-	// Read and run fn for all remaining items
+	// Read and run fn for all remaining items. When errors are isolated a failed query does not stop the remaining
+	// callbacks but its error is still returned.
+	var itemErr error
 	for br.err == nil && !br.closed && br.b != nil && br.qqIdx < len(br.b.queuedQueries) {
 		if br.b.queuedQueries[br.qqIdx].fn != nil {
 			err := br.b.queuedQueries[br.qqIdx].fn(br)
-			if err != nil && br.err == nil {
-				br.err = err
+			if br.isItemError(err) {
+				if itemErr == nil {
+					itemErr = br.itemErrs.previous(err)
+				}
+			} else if err != nil && br.err == nil {
+				br.err = br.itemErrs.previous(err)
 			}
 		} else {
-			br.Exec()
+			_, err := br.Exec()
+			if br.isItemError(err) && itemErr == nil {
+				itemErr = br.itemErrs.previous(err)
+			}
 		}
 	}
 
 	br.closed = true
 
-	err := br.pipeline.Close()
-	if br.err == nil {
-		br.err = err
+	// The pipeline is closed even if an error occurred so the connection is not left busy. If the context is done this
+	// closes the connection instead of discarding the remaining results.
+	if br.pipeline != nil {
+		err := br.pipeline.Close()
+		if br.err == nil {
+			br.err = br.itemErrs.previous(err)
+		}
 	}
 
+	if br.err == nil {
+		return itemErr
+	}
 	return br.err
 }
 
+// isClosed returns true if Close has been called. endTraced is set by the first call to Close.
+func (br *pipelineBatchResults) isClosed() bool {
+	return br.endTraced
+}
+
+// isItemError returns true if err is an error returned by the server for a single query and errors are isolated.
+func (br *pipelineBatchResults) isItemError(err error) bool {
+	if !br.isolateErrors {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr)
+}
+
+// beginItem prepares to read the results of the next query when errors are isolated. It discards the remaining results
+// of the previous query through its sync and the result of the savepoint statement that precedes the query.
+func (br *pipelineBatchResults) beginItem() error {
+	for br.itemSyncPending {
+		results, err := br.pipeline.GetResults()
+		if err != nil {
+			if br.isItemError(err) {
+				continue
+			}
+			return err
+		}
+
+		switch results := results.(type) {
+		case *pgconn.ResultReader:
+			if _, err := results.Close(); err != nil && !br.isItemError(err) {
+				return err
+			}
+		case *pgconn.PipelineSync:
+			br.itemSyncPending = false
+		case nil:
+			return errors.New("expected sync, got no results")
+		default:
+			return fmt.Errorf("unexpected pipeline result: %T", results)
+		}
+	}
+
+	if br.savepoints {
+		results, err := br.pipeline.GetResults()
+		if err != nil {
+			return err
+		}
+		rr, ok := results.(*pgconn.ResultReader)
+		if !ok {
+			return fmt.Errorf("unexpected pipeline result: %T", results)
+		}
+		if _, err := rr.Close(); err != nil {
+			return err
+		}
+	}
+
+	br.itemSyncPending = true
+	return nil
+}
+
 func (br *pipelineBatchResults) earlyError() error {
 	return br.err
 }
@@ -4,16 +4,70 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func TestBatchLen(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	require.Equal(t, 0, batch.Len())
+
+	batch.Queue("select 1")
+	batch.Queue("select $1::int", 2)
+	require.Equal(t, 2, batch.Len())
+}
+
+func TestBatchReset(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	require.NoError(t, batch.Reset())
+	require.Equal(t, 0, batch.Len())
+}
+
+func TestConnSendBatchReset(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+
+		br := conn.SendBatch(ctx, batch)
+		require.Error(t, batch.Reset())
+		require.Equal(t, 2, batch.Len())
+
+		var n int32
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 1, n)
+		require.NoError(t, br.Close())
+
+		require.NoError(t, batch.Reset())
+		require.Equal(t, 0, batch.Len())
+
+		batch.Queue("select $1::int", 3)
+		br = conn.SendBatch(ctx, batch)
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 3, n)
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
 func TestConnSendBatch(t *testing.T) {
 	t.Parallel()
 
@@ -242,6 +296,203 @@ func TestConnSendBatchQueuedQuery(t *testing.T) {
 	})
 }
 
+func TestConnSendBatchQueueFunc(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+
+		var n int32
+		var ns []int32
+		qq1 := batch.QueueFunc("select n from generate_series(1, $1::int) n", []any{3}, []any{&n}, func() error {
+			ns = append(ns, n)
+			return nil
+		})
+
+		var s string
+		var ss []string
+		qq2 := batch.QueueFunc("select 'a' union all select 'b'", nil, []any{&s}, func() error {
+			ss = append(ss, s)
+			return nil
+		})
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		require.Equal(t, []int32{1, 2, 3}, ns)
+		require.Equal(t, []string{"a", "b"}, ss)
+		require.True(t, qq1.CommandTag().Select())
+		require.EqualValues(t, 3, qq1.CommandTag().RowsAffected())
+		require.EqualValues(t, 2, qq2.CommandTag().RowsAffected())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchQueueSimple(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table events(
+	  id int primary key,
+	  name text,
+	  occurred_at timestamptz,
+	  tags int[]
+	);`)
+
+		occurredAt := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+		batch := &pgx.Batch{}
+		batch.QueueSimple("insert into events(id, name, occurred_at, tags) values($1, $2, $3, $4)", 1, "a", occurredAt, []int32{1, 2})
+		batch.QueueSimple("insert into events(id, name, occurred_at, tags) values($1, $2, $3, $4)", 2, nil, nil, nil)
+		batch.QueueSimple("select id, name, occurred_at, tags from events order by id")
+
+		br := conn.SendBatch(ctx, batch)
+
+		for i := 0; i < 2; i++ {
+			ct, err := br.Exec()
+			require.NoError(t, err)
+			require.EqualValues(t, 1, ct.RowsAffected())
+		}
+
+		type event struct {
+			ID         int32
+			Name       *string
+			OccurredAt *time.Time
+			Tags       []int32
+		}
+
+		rows, err := br.Query()
+		require.NoError(t, err)
+		events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[event])
+		require.NoError(t, err)
+
+		require.Len(t, events, 2)
+		require.EqualValues(t, 1, events[0].ID)
+		require.Equal(t, "a", *events[0].Name)
+		require.True(t, occurredAt.Equal(*events[0].OccurredAt))
+		require.Equal(t, []int32{1, 2}, events[0].Tags)
+		require.Equal(t, event{ID: 2}, events[1])
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchSimple(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support CREATE INDEX CONCURRENTLY")
+
+		mustExec(t, conn, `drop table if exists send_batch_simple_widgets; drop type if exists send_batch_simple_color;`)
+		defer mustExec(t, conn, `drop table if exists send_batch_simple_widgets; drop type if exists send_batch_simple_color;`)
+
+		batch := &pgx.Batch{}
+		batch.Queue(`create type send_batch_simple_color as enum ('red', 'green')`)
+		batch.Queue(`create table send_batch_simple_widgets(
+  id int primary key,
+  color send_batch_simple_color not null
+) -- trailing comment`)
+		batch.Queue(`insert into send_batch_simple_widgets(id, color) values (1, 'red'), (2, 'green');`)
+		err := conn.SendBatchSimple(ctx, batch)
+		require.NoError(t, err)
+
+		var n int64
+		err = conn.QueryRow(ctx, `select count(*) from send_batch_simple_widgets where color = 'green'`).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		// CREATE INDEX CONCURRENTLY cannot run in the implicit transaction of a multi-statement batch. The whole batch is
+		// rolled back.
+		batch = &pgx.Batch{}
+		batch.Queue(`insert into send_batch_simple_widgets(id, color) values (3, 'red')`)
+		batch.Queue(`create index concurrently send_batch_simple_widgets_color_idx on send_batch_simple_widgets(color)`)
+		err = conn.SendBatchSimple(ctx, batch)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "25001", pgErr.Code)
+
+		err = conn.QueryRow(ctx, `select count(*) from send_batch_simple_widgets`).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+
+		// It succeeds when it is the only query in the batch.
+		batch = &pgx.Batch{}
+		batch.Queue(`create index concurrently send_batch_simple_widgets_color_idx on send_batch_simple_widgets(color)`)
+		err = conn.SendBatchSimple(ctx, batch)
+		require.NoError(t, err)
+
+		var indexCount int64
+		err = conn.QueryRow(ctx, `select count(*) from pg_indexes where indexname = 'send_batch_simple_widgets_color_idx'`).Scan(&indexCount)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, indexCount)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchSimpleRejectsArguments(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue(`create temporary table send_batch_simple_args(id int)`)
+		batch.Queue(`insert into send_batch_simple_args(id) values ($1)`, 1)
+		err := conn.SendBatchSimple(ctx, batch)
+		require.EqualError(t, err, "SendBatchSimple: query 1 has arguments")
+
+		batch = &pgx.Batch{}
+		batch.Queue(`create temporary table send_batch_simple_args(id int)`)
+		batch.Queue(`insert into send_batch_simple_args(id) values (1)`).Exec(func(ct pgconn.CommandTag) error { return nil })
+		err = conn.SendBatchSimple(ctx, batch)
+		require.EqualError(t, err, "SendBatchSimple: query 1 has a result function")
+
+		// Nothing was sent.
+		var exists bool
+		err = conn.QueryRow(ctx, `select to_regclass('pg_temp.send_batch_simple_args') is not null`).Scan(&exists)
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select pg_sleep(10)")
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		br := conn.SendBatch(ctx, batch)
+		_, err := br.Exec()
+		if err == nil {
+			_, err = br.Exec()
+		}
+		require.Error(t, err)
+		require.True(t, pgconn.Timeout(err), "%v", err)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, time.Since(start), 5*time.Second)
+
+		br.Close()
+
+		select {
+		case <-conn.PgConn().CleanupDone():
+		case <-time.After(5 * time.Second):
+			t.Fatal("connection not closed")
+		}
+		require.True(t, conn.IsClosed())
+	})
+}
+
 func TestConnSendBatchMany(t *testing.T) {
 	t.Parallel()
 
@@ -508,15 +759,17 @@ func TestConnSendBatchQueryError(t *testing.T) {
 			}
 		}
 
-		if pgErr, ok := rows.Err().(*pgconn.PgError); !(ok && pgErr.Code == "22012") {
+		var pgErr *pgconn.PgError
+		if !(errors.As(rows.Err(), &pgErr) && pgErr.Code == "22012") {
 			t.Errorf("rows.Err() => %v, want error code %v", rows.Err(), 22012)
 		}
 
 		err = br.Close()
-		if pgErr, ok := err.(*pgconn.PgError); !(ok && pgErr.Code == "22012") {
+		if !(errors.As(err, &pgErr) && pgErr.Code == "22012") {
 			t.Errorf("br.Close() => %v, want error code %v", err, 22012)
 		}
 
+		ensureConnValid(t, conn)
 	})
 }
 
@@ -532,7 +785,8 @@ func TestConnSendBatchQuerySyntaxError(t *testing.T) {
 
 		var n int32
 		err := br.QueryRow().Scan(&n)
-		if pgErr, ok := err.(*pgconn.PgError); !(ok && pgErr.Code == "42601") {
+		var pgErr *pgconn.PgError
+		if !(errors.As(err, &pgErr) && pgErr.Code == "42601") {
 			t.Errorf("rows.Err() => %v, want error code %v", err, 42601)
 		}
 
@@ -541,168 +795,840 @@ func TestConnSendBatchQuerySyntaxError(t *testing.T) {
 			t.Error("Expected error")
 		}
 
+		ensureConnValid(t, conn)
 	})
 }
 
-func TestConnSendBatchQueryRowInsert(t *testing.T) {
+func TestConnSendBatchExecConstraintError(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
-
-		sql := `create temporary table ledger(
-	  id serial primary key,
-	  description varchar not null,
-	  amount int not null
-	);`
-		mustExec(t, conn, sql)
+		mustExec(t, conn, `create temporary table batch_constraint (
+	id int primary key,
+	constraint batch_constraint_id_positive check (id > 0)
+);`)
 
 		batch := &pgx.Batch{}
-		batch.Queue("select 1")
-		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+		batch.Queue("insert into batch_constraint (id) values (1)")
+		batch.Queue("insert into batch_constraint (id) values (-1)")
 
 		br := conn.SendBatch(context.Background(), batch)
 
-		var value int
-		err := br.QueryRow().Scan(&value)
-		if err != nil {
-			t.Error(err)
-		}
-
-		ct, err := br.Exec()
-		if err != nil {
-			t.Error(err)
-		}
-		if ct.RowsAffected() != 2 {
-			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
-		}
+		_, err := br.Exec()
+		require.NoError(t, err)
 
-		br.Close()
+		_, err = br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23514", pgErr.Code)
+		require.Equal(t, "batch_constraint_id_positive", pgErr.ConstraintName)
+		require.Equal(t, "batch_constraint", pgErr.TableName)
+		require.Equal(t, "ERROR", pgErr.Severity)
+		require.NotEmpty(t, pgErr.SchemaName)
 
+		err = br.Close()
+		require.ErrorAs(t, err, &pgErr)
 	})
 }
 
-func TestConnSendBatchQueryPartialReadInsert(t *testing.T) {
+func TestConnSendBatchItemError(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
-
-		sql := `create temporary table ledger(
-	  id serial primary key,
-	  description varchar not null,
-	  amount int not null
-	);`
-		mustExec(t, conn, sql)
+		mustExec(t, conn, `create temporary table batch_item_error (id int primary key);`)
 
 		batch := &pgx.Batch{}
-		batch.Queue("select 1 union all select 2 union all select 3")
-		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+		batch.Queue("insert into batch_item_error (id) values (1)")
+		batch.Queue("insert into batch_item_error (id) values (2)")
+		batch.Queue("select count(*) from batch_item_error")
+		batch.Queue("insert into batch_item_error (id) values (1)")
+		batch.Queue("insert into batch_item_error (id) values (3)")
 
-		br := conn.SendBatch(context.Background(), batch)
+		br := conn.SendBatch(ctx, batch)
 
-		rows, err := br.Query()
-		if err != nil {
-			t.Error(err)
+		for i := 0; i < 3; i++ {
+			_, err := br.Exec()
+			require.NoError(t, err)
 		}
-		rows.Close()
 
-		ct, err := br.Exec()
-		if err != nil {
-			t.Error(err)
-		}
-		if ct.RowsAffected() != 2 {
-			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
-		}
+		_, err := br.Exec()
+		var itemErr *pgx.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		require.Equal(t, 3, itemErr.Index)
+		require.Equal(t, "insert into batch_item_error (id) values (1)", itemErr.SQL)
+		require.Empty(t, itemErr.StatementName)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+		require.Equal(t, "batch item 3 (query: insert into batch_item_error (id) values (1)) failed: "+pgErr.Error(), err.Error())
 
-		br.Close()
+		err = br.Close()
+		require.ErrorAs(t, err, &itemErr)
+		require.Equal(t, 3, itemErr.Index)
 
+		ensureConnValid(t, conn)
 	})
 }
 
-func TestTxSendBatch(t *testing.T) {
+func TestConnSendBatchCloseReturnsItemError(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 1/0").QueryRow(func(row pgx.Row) error {
+			var n int32
+			return row.Scan(&n)
+		})
+		batch.Queue("select 2")
+
+		// Close reads the results that were not read and still identifies the query that failed.
+		err := conn.SendBatch(ctx, batch).Close()
+		var itemErr *pgx.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		require.Equal(t, 1, itemErr.Index)
+		require.Equal(t, "select 1/0", itemErr.SQL)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
 
-		sql := `create temporary table ledger1(
-	  id serial primary key,
-	  description varchar not null
-	);`
-		mustExec(t, conn, sql)
+func TestConnSendBatchCommitErrorIsNotItemError(t *testing.T) {
+	t.Parallel()
 
-		sql = `create temporary table ledger2(
-	  id int primary key,
-	  amount int not null
-	);`
-		mustExec(t, conn, sql)
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support deferred constraints")
 
-		tx, _ := conn.Begin(context.Background())
-		batch := &pgx.Batch{}
-		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+		mustExec(t, conn, `create temporary table batch_commit_error (id int unique deferrable initially deferred);`)
 
-		br := tx.SendBatch(context.Background(), batch)
+		batch := &pgx.Batch{}
+		batch.Queue("insert into batch_commit_error (id) values (1)")
+		batch.Queue("insert into batch_commit_error (id) values (1)")
 
-		var id int
-		err := br.QueryRow().Scan(&id)
-		if err != nil {
-			t.Error(err)
+		br := conn.SendBatch(ctx, batch)
+		for i := 0; i < 2; i++ {
+			_, err := br.Exec()
+			require.NoError(t, err)
 		}
-		br.Close()
 
-		batch = &pgx.Batch{}
-		batch.Queue("insert into ledger2(id,amount) values($1, $2)", id, 2)
-		batch.Queue("select amount from ledger2 where id = $1", id)
+		// The unique constraint is checked when the implicit transaction commits after all queries succeeded.
+		err := br.Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+		var itemErr *pgx.BatchItemError
+		require.False(t, errors.As(err, &itemErr))
 
-		br = tx.SendBatch(context.Background(), batch)
+		ensureConnValid(t, conn)
+	})
+}
 
-		ct, err := br.Exec()
-		if err != nil {
-			t.Error(err)
-		}
-		if ct.RowsAffected() != 1 {
-			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 1)
-		}
+func TestConnSendBatchItemErrorPreparedStatement(t *testing.T) {
+	t.Parallel()
 
-		var amount int
-		err = br.QueryRow().Scan(&amount)
-		if err != nil {
-			t.Error(err)
-		}
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
 
-		br.Close()
-		tx.Commit(context.Background())
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table batch_item_error (id int primary key);`)
 
-		var count int
-		conn.QueryRow(context.Background(), "select count(1) from ledger1 where id = $1", id).Scan(&count)
-		if count != 1 {
-			t.Errorf("count => %v, want %v", count, 1)
-		}
+		_, err := conn.Prepare(ctx, "batch_item_error_insert", "insert into batch_item_error (id) values ($1)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Queue("batch_item_error_insert", 1)
+		batch.Queue("batch_item_error_insert", 1)
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		var itemErr *pgx.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		require.Equal(t, 1, itemErr.Index)
+		require.Equal(t, "batch_item_error_insert", itemErr.StatementName)
+		require.Equal(t, "insert into batch_item_error (id) values ($1)", itemErr.SQL)
+		require.Contains(t, err.Error(), "batch item 1 (prepared statement batch_item_error_insert, query: insert into batch_item_error (id) values ($1)) failed: ")
 
 		err = br.Close()
-		if err != nil {
-			t.Fatal(err)
-		}
+		require.ErrorAs(t, err, &itemErr)
+		require.Equal(t, 1, itemErr.Index)
 
+		ensureConnValid(t, conn)
 	})
 }
 
-func TestTxSendBatchRollback(t *testing.T) {
+func TestConnSendBatchErrorRollsBackImplicitTransaction(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table batch_implicit_tx (id int primary key);`)
 
-		sql := `create temporary table ledger1(
-	  id serial primary key,
-	  description varchar not null
-	);`
-		mustExec(t, conn, sql)
-
-		tx, _ := conn.Begin(context.Background())
 		batch := &pgx.Batch{}
-		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+		batch.Queue("insert into batch_implicit_tx (id) values (1)")
+		batch.Queue("insert into batch_implicit_tx (id) values (1)")
+		batch.Queue("insert into batch_implicit_tx (id) values (2)")
 
-		br := tx.SendBatch(context.Background(), batch)
+		err := conn.SendBatch(context.Background(), batch).Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
 
-		var id int
+		var n int64
+		err = conn.QueryRow(context.Background(), "select count(*) from batch_implicit_tx").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+	})
+}
+
+func TestConnSendBatchQueueCopyFrom(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeCacheDescribe, pgx.QueryExecModeDescribeExec}
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table batch_copy (id int4, name text);
+insert into batch_copy values (100, 'old');`)
+
+		rows := make([][]any, 0, 10000)
+		for i := 0; i < 10000; i++ {
+			rows = append(rows, []any{int32(i), fmt.Sprintf("name %d", i)})
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue("truncate batch_copy")
+		batch.QueueCopyFrom(pgx.Identifier{"batch_copy"}, []string{"id", "name"}, pgx.CopyFromRows(rows))
+		batch.Queue("select count(*), max(id) from batch_copy")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		ct, err := br.Exec()
+		require.NoError(t, err)
+		require.EqualValues(t, 10000, ct.RowsAffected())
+
+		var count int64
+		var maxID int32
+		err = br.QueryRow().Scan(&count, &maxID)
+		require.NoError(t, err)
+		require.EqualValues(t, 10000, count)
+		require.EqualValues(t, 9999, maxID)
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		// An error in the copy fails the batch and rolls back the preceding queries.
+		batch = &pgx.Batch{}
+		batch.Queue("truncate batch_copy")
+		batch.QueueCopyFrom(pgx.Identifier{"batch_copy"}, []string{"id", "name"}, pgx.CopyFromRows([][]any{{int32(1), "a"}, {int32(2), "a\x00b"}}))
+		err = conn.SendBatch(ctx, batch).Close()
+		require.Error(t, err)
+
+		err = conn.QueryRow(ctx, "select count(*) from batch_copy").Scan(&count)
+		require.NoError(t, err)
+		require.EqualValues(t, 10000, count)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchQueueCopyFromUnsupportedMode(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol}
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.QueueCopyFrom(pgx.Identifier{"batch_copy"}, []string{"id"}, pgx.CopyFromRows(nil))
+		err := conn.SendBatch(ctx, batch).Close()
+		require.ErrorContains(t, err, "QueueCopyFrom cannot be used")
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchQueryStreamsRows(t *testing.T) {
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		const rowCount = 1000
+		const rowSize = 100
+
+		// The server sleeps before producing the last row. The rows before it fill the server's send buffer many times
+		// over so they are sent while it sleeps.
+		batch := &pgx.Batch{}
+		batch.Queue("select repeat('x', $1::int) from generate_series(1, $2::int) n where pg_sleep(case when n = $2::int then 1 else 0 end)::text = ''", rowSize, rowCount)
+		batch.Queue("select 42")
+
+		br := conn.SendBatch(ctx, batch)
+
+		rows, err := br.Query()
+		require.NoError(t, err)
+
+		var n int
+		var firstRowTime, lastRowTime time.Time
+		for rows.Next() {
+			require.Len(t, rows.RawValues()[0], rowSize)
+			n++
+			if n == 1 {
+				firstRowTime = time.Now()
+			}
+			lastRowTime = time.Now()
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, rowCount, n)
+
+		// If the result were buffered the first row would only be available after the server finished sleeping.
+		require.Greater(t, lastRowTime.Sub(firstRowTime), 500*time.Millisecond)
+
+		var x int32
+		err = br.QueryRow().Scan(&x)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, x)
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchQueryRow(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 1 where false")
+
+		br := conn.SendBatch(ctx, batch)
+
+		row, err := pgx.BatchQueryRow(br)
+		require.NoError(t, err)
+		var n int32
+		err = row.Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		row, err = pgx.BatchQueryRow(br)
+		require.NoError(t, err)
+		err = row.Scan(&n)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchCollectRows(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		type Timestamps struct {
+			CreatedAt time.Time `db:"created_at"`
+		}
+		type widget struct {
+			ID   int32
+			Name string `db:"widget_name"`
+			Timestamps
+		}
+
+		createdAt := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select n as id, 'w' || n as widget_name, $1::timestamptz as created_at from generate_series(1, 2) n", createdAt)
+		batch.Queue("select n as id, 'w' || n as widget_name from generate_series(1, 2) n")
+
+		br := conn.SendBatch(ctx, batch)
+
+		widgets, err := pgx.BatchCollectRows(br, pgx.RowToStructByName[widget])
+		require.NoError(t, err)
+		require.Len(t, widgets, 2)
+		require.Equal(t, int32(1), widgets[0].ID)
+		require.Equal(t, "w1", widgets[0].Name)
+		require.True(t, createdAt.Equal(widgets[0].CreatedAt))
+		require.Equal(t, int32(2), widgets[1].ID)
+		require.Equal(t, "w2", widgets[1].Name)
+
+		maps, err := pgx.BatchCollectRows(br, pgx.RowToMap)
+		require.NoError(t, err)
+		require.Equal(t, []map[string]any{
+			{"id": int32(1), "widget_name": "w1"},
+			{"id": int32(2), "widget_name": "w2"},
+		}, maps)
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchExecRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table batch_rows_affected(id int primary key, n int not null);
+insert into batch_rows_affected(id, n) select g, 0 from generate_series(1, 10) g;`)
+
+		batch := &pgx.Batch{}
+		batch.Queue("update batch_rows_affected set n = n + 1 where id <= $1", 5)
+		batch.Queue("update batch_rows_affected set n = n + 1 where id = $1", 1)
+		batch.Queue("update batch_rows_affected set n = n + 1 where id > $1", 100)
+		batch.Queue("delete from batch_rows_affected where id > $1", 8)
+
+		br := conn.SendBatch(ctx, batch)
+
+		var total int64
+		for _, want := range []int64{5, 1, 0} {
+			ct, err := br.Exec()
+			require.NoError(t, err)
+			require.True(t, ct.Update())
+			require.Equal(t, want, ct.RowsAffected())
+			total += ct.RowsAffected()
+		}
+		require.EqualValues(t, 6, total)
+
+		ct, err := br.Exec()
+		require.NoError(t, err)
+		require.True(t, ct.Delete())
+		require.EqualValues(t, 2, ct.RowsAffected())
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchRawValues(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 42::int4, null::int4")
+		batch.Queue("select 'x'::text")
+
+		br := conn.SendBatch(ctx, batch)
+
+		rows, err := br.Query()
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		rawValues := rows.RawValues()
+		require.Len(t, rawValues, 2)
+		require.Nil(t, rawValues[1])
+		if rows.FieldDescriptions()[0].Format == pgtype.BinaryFormatCode {
+			require.Equal(t, []byte{0, 0, 0, 42}, rawValues[0])
+		} else {
+			require.Equal(t, []byte("42"), rawValues[0])
+		}
+		require.False(t, rows.Next())
+		require.NoError(t, rows.Err())
+
+		rows, err = br.Query()
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		require.Equal(t, [][]byte{[]byte("x")}, rows.RawValues())
+		rows.Close()
+		require.NoError(t, rows.Err())
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchSingleStatementWithSemicolons(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 'a;b' -- c;d\n;")
+		batch.Queue("select $$e;f$$::text")
+
+		br := conn.SendBatch(ctx, batch)
+
+		var s string
+		err := br.QueryRow().Scan(&s)
+		require.NoError(t, err)
+		require.Equal(t, "a;b", s)
+
+		err = br.QueryRow().Scan(&s)
+		require.NoError(t, err)
+		require.Equal(t, "e;f", s)
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchMultipleStatements(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2; select 3")
+		batch.Queue("select 4")
+
+		br := conn.SendBatch(ctx, batch)
+
+		var n int32
+		err := br.QueryRow().Scan(&n)
+		require.EqualError(t, err, "batch query 1 contains multiple statements: queue each statement separately")
+
+		err = br.Close()
+		require.EqualError(t, err, "batch query 1 contains multiple statements: queue each statement separately")
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchQueryRowCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		br := conn.SendBatch(ctx, batch)
+		row, err := pgx.BatchQueryRow(br)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, row)
+		br.Close()
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchQueryRowClosedConn(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		// Close the network connection out from under the pgx.Conn so the batch cannot be sent or read.
+		conn.PgConn().Conn().Close()
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+
+		br := conn.SendBatch(ctx, batch)
+		row, err := pgx.BatchQueryRow(br)
+		require.Error(t, err)
+		require.Nil(t, row)
+		br.Close()
+	})
+}
+
+func TestConnSendBatchIsolateErrors(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeCacheDescribe, pgx.QueryExecModeDescribeExec}
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table batch_isolate (id int primary key);`)
+
+		for _, inTx := range []bool{false, true} {
+			mustExec(t, conn, "delete from batch_isolate")
+
+			var tx pgx.Tx
+			var err error
+			if inTx {
+				tx, err = conn.Begin(ctx)
+				require.NoError(t, err)
+			}
+
+			batch := &pgx.Batch{IsolateErrors: true}
+			batch.Queue("insert into batch_isolate (id) values (1)")
+			batch.Queue("insert into batch_isolate (id) values (1)")
+			batch.Queue("select id from batch_isolate")
+			batch.Queue("select 1/0")
+			batch.Queue("insert into batch_isolate (id) values (2)")
+
+			br := conn.SendBatch(ctx, batch)
+
+			_, err = br.Exec()
+			require.NoError(t, err)
+
+			_, err = br.Exec()
+			var pgErr *pgconn.PgError
+			require.ErrorAs(t, err, &pgErr)
+			require.Equal(t, "23505", pgErr.Code)
+
+			rows, err := br.Query()
+			require.NoError(t, err)
+			ids, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+			require.NoError(t, err)
+			require.Equal(t, []int32{1}, ids)
+
+			var n int32
+			err = br.QueryRow().Scan(&n)
+			require.ErrorAs(t, err, &pgErr)
+			require.Equal(t, "22012", pgErr.Code)
+
+			_, err = br.Exec()
+			require.NoError(t, err)
+
+			require.NoError(t, br.Close())
+
+			if inTx {
+				require.NoError(t, tx.Commit(ctx))
+			}
+
+			rows, err = conn.Query(ctx, "select id from batch_isolate order by id")
+			require.NoError(t, err)
+			ids, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+			require.NoError(t, err)
+			require.Equal(t, []int32{1, 2}, ids)
+		}
+
+		// Without IsolateErrors the first error aborts the batch and rolls back the preceding queries.
+		mustExec(t, conn, "delete from batch_isolate")
+		batch := &pgx.Batch{}
+		batch.Queue("insert into batch_isolate (id) values (1)")
+		batch.Queue("insert into batch_isolate (id) values (1)")
+		batch.Queue("insert into batch_isolate (id) values (2)")
+		err := conn.SendBatch(ctx, batch).Close()
+		require.Error(t, err)
+
+		var count int64
+		err = conn.QueryRow(ctx, "select count(*) from batch_isolate").Scan(&count)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, count)
+	})
+}
+
+func TestConnSendBatchIsolateErrorsClose(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{IsolateErrors: true}
+		batch.Queue("select 1/0")
+		var n int32
+		batch.Queue("select 2").QueryRow(func(row pgx.Row) error {
+			return row.Scan(&n)
+		})
+
+		err := conn.SendBatch(ctx, batch).Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+		require.EqualValues(t, 2, n)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchItemFields(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1::int4 as a, 'foo'::text as b")
+		batch.Queue("select now() as c")
+		batch.Queue("set search_path to public")
+
+		require.Nil(t, batch.ItemFields(0))
+
+		br := conn.SendBatch(ctx, batch)
+
+		fields := batch.ItemFields(0)
+		require.Len(t, fields, 2)
+		require.Equal(t, "a", fields[0].Name)
+		require.EqualValues(t, pgtype.Int4OID, fields[0].DataTypeOID)
+		require.Equal(t, "b", fields[1].Name)
+		require.EqualValues(t, pgtype.TextOID, fields[1].DataTypeOID)
+
+		fields = batch.ItemFields(1)
+		require.Len(t, fields, 1)
+		require.EqualValues(t, pgtype.TimestamptzOID, fields[0].DataTypeOID)
+
+		require.Empty(t, batch.ItemFields(2))
+		require.Nil(t, batch.ItemFields(3))
+		require.Nil(t, batch.ItemFields(-1))
+
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestConnSendBatchQueryRowInsert(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger(
+	  id serial primary key,
+	  description varchar not null,
+	  amount int not null
+	);`
+		mustExec(t, conn, sql)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+
+		br := conn.SendBatch(context.Background(), batch)
+
+		var value int
+		err := br.QueryRow().Scan(&value)
+		if err != nil {
+			t.Error(err)
+		}
+
+		ct, err := br.Exec()
+		if err != nil {
+			t.Error(err)
+		}
+		if ct.RowsAffected() != 2 {
+			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
+		}
+
+		br.Close()
+
+	})
+}
+
+func TestConnSendBatchQueryPartialReadInsert(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger(
+	  id serial primary key,
+	  description varchar not null,
+	  amount int not null
+	);`
+		mustExec(t, conn, sql)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1 union all select 2 union all select 3")
+		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+
+		br := conn.SendBatch(context.Background(), batch)
+
+		rows, err := br.Query()
+		if err != nil {
+			t.Error(err)
+		}
+		rows.Close()
+
+		ct, err := br.Exec()
+		if err != nil {
+			t.Error(err)
+		}
+		if ct.RowsAffected() != 2 {
+			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
+		}
+
+		br.Close()
+
+	})
+}
+
+func TestTxSendBatch(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger1(
+	  id serial primary key,
+	  description varchar not null
+	);`
+		mustExec(t, conn, sql)
+
+		sql = `create temporary table ledger2(
+	  id int primary key,
+	  amount int not null
+	);`
+		mustExec(t, conn, sql)
+
+		tx, _ := conn.Begin(context.Background())
+		batch := &pgx.Batch{}
+		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+
+		br := tx.SendBatch(context.Background(), batch)
+
+		var id int
+		err := br.QueryRow().Scan(&id)
+		if err != nil {
+			t.Error(err)
+		}
+		br.Close()
+
+		batch = &pgx.Batch{}
+		batch.Queue("insert into ledger2(id,amount) values($1, $2)", id, 2)
+		batch.Queue("select amount from ledger2 where id = $1", id)
+
+		br = tx.SendBatch(context.Background(), batch)
+
+		ct, err := br.Exec()
+		if err != nil {
+			t.Error(err)
+		}
+		if ct.RowsAffected() != 1 {
+			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 1)
+		}
+
+		var amount int
+		err = br.QueryRow().Scan(&amount)
+		if err != nil {
+			t.Error(err)
+		}
+
+		br.Close()
+		tx.Commit(context.Background())
+
+		var count int
+		conn.QueryRow(context.Background(), "select count(1) from ledger1 where id = $1", id).Scan(&count)
+		if count != 1 {
+			t.Errorf("count => %v, want %v", count, 1)
+		}
+
+		err = br.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	})
+}
+
+func TestTxSendBatchRollback(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger1(
+	  id serial primary key,
+	  description varchar not null
+	);`
+		mustExec(t, conn, sql)
+
+		tx, _ := conn.Begin(context.Background())
+		batch := &pgx.Batch{}
+		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+
+		br := tx.SendBatch(context.Background(), batch)
+
+		var id int
 		err := br.QueryRow().Scan(&id)
 		if err != nil {
 			t.Error(err)
@@ -760,7 +1686,8 @@ func TestConnBeginBatchDeferredError(t *testing.T) {
 			t.Fatal("expected error 23505 but got none")
 		}
 
-		if err, ok := err.(*pgconn.PgError); !ok || err.Code != "23505" {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
 			t.Fatalf("expected error 23505, got %v", err)
 		}
 
@@ -923,3 +1850,118 @@ func ExampleConn_SendBatch() {
 	// 3
 	// 5
 }
+
+func TestConnSendBatchResultFormatsChosenPerColumn(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := `select 42::int4, 1.25::numeric, '{"a": [1, "b"]}'::jsonb`
+
+		batch := &pgx.Batch{}
+		batch.Queue(sql)
+		batch.QueueSimple(sql)
+
+		br := conn.SendBatch(ctx, batch)
+		for i := 0; i < 2; i++ {
+			rows, err := br.Query()
+			require.NoError(t, err)
+
+			var n int32
+			var f float64
+			var m map[string]any
+			for rows.Next() {
+				err = rows.Scan(&n, &f, &m)
+				require.NoError(t, err)
+			}
+			require.NoError(t, rows.Err())
+
+			require.EqualValues(t, 42, n)
+			require.Equal(t, 1.25, f)
+			require.Equal(t, map[string]any{"a": []any{float64(1), "b"}}, m)
+		}
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+// maxWriteConn records the size of the largest write to the wrapped net.Conn.
+type maxWriteConn struct {
+	net.Conn
+	max *int
+}
+
+func (c maxWriteConn) Write(p []byte) (int, error) {
+	if len(p) > *c.max {
+		*c.max = len(p)
+	}
+	return c.Conn.Write(p)
+}
+
+func TestConnSendBatchMaxBufferedBytes(t *testing.T) {
+	t.Parallel()
+
+	var maxWrite int
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		dialFunc := config.DialFunc
+		config.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialFunc(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return maxWriteConn{Conn: conn, max: &maxWrite}, nil
+		}
+		return config
+	}
+
+	modes := []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeCacheDescribe, pgx.QueryExecModeDescribeExec}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, "create temporary table batch_max_buffered(id int primary key, s text not null)")
+
+		const queryCount = 2000
+		const maxBufferedBytes = 64 * 1024
+		s := strings.Repeat("x", 1000)
+
+		batch := &pgx.Batch{MaxBufferedBytes: maxBufferedBytes}
+		for i := 0; i < queryCount; i++ {
+			batch.Queue("insert into batch_max_buffered(id, s) values($1, $2)", i, s)
+		}
+
+		maxWrite = 0
+		br := conn.SendBatch(ctx, batch)
+		for i := 0; i < queryCount; i++ {
+			ct, err := br.Exec()
+			require.NoError(t, err)
+			require.EqualValues(t, 1, ct.RowsAffected())
+		}
+		require.NoError(t, br.Close())
+
+		// Each write holds about MaxBufferedBytes plus the message overhead of the queries instead of the whole batch.
+		require.Less(t, maxWrite, 2*maxBufferedBytes)
+
+		var n int64
+		err := conn.QueryRow(ctx, "select count(*) from batch_max_buffered").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, queryCount, n)
+
+		// All writes are part of the same implicit transaction so a failure at the end rolls back the entire batch.
+		batch = &pgx.Batch{MaxBufferedBytes: maxBufferedBytes}
+		for i := 0; i < queryCount; i++ {
+			batch.Queue("insert into batch_max_buffered(id, s) values($1, $2)", queryCount+i, s)
+		}
+		batch.Queue("insert into batch_max_buffered(id, s) values($1, $2)", 0, s)
+		err = conn.SendBatch(ctx, batch).Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+
+		err = conn.QueryRow(ctx, "select count(*) from batch_max_buffered").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, queryCount, n)
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -756,6 +756,62 @@ func benchmarkMultipleQueriesBatch(b *testing.B, conn *pgx.Conn, queryCount int)
 	}
 }
 
+// writeCountingConn counts the bytes written to the wrapped net.Conn.
+type writeCountingConn struct {
+	net.Conn
+	n *int64
+}
+
+func (c writeCountingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// BenchmarkSendBatchIdenticalQueries reports the bytes written to send a batch of identical queries. With
+// QueryExecModeCacheStatement the query is parsed once as a prepared statement and each item only binds to it. The
+// other modes parse the query for every item.
+func BenchmarkSendBatchIdenticalQueries(b *testing.B) {
+	const queryCount = 500
+
+	for _, mode := range []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeCacheDescribe, pgx.QueryExecModeDescribeExec} {
+		b.Run(mode.String(), func(b *testing.B) {
+			var bytesWritten int64
+
+			config := mustParseConfig(b, os.Getenv("PGX_TEST_DATABASE"))
+			config.DefaultQueryExecMode = mode
+			dialFunc := config.DialFunc
+			config.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				conn, err := dialFunc(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				return writeCountingConn{Conn: conn, n: &bytesWritten}, nil
+			}
+
+			conn := mustConnect(b, config)
+			defer closeConn(b, conn)
+
+			sql := "select $1::int4, 'a somewhat longer query text that is repeated for every queued query'"
+
+			b.ResetTimer()
+			bytesWritten = 0
+			for i := 0; i < b.N; i++ {
+				batch := &pgx.Batch{}
+				for j := 0; j < queryCount; j++ {
+					batch.Queue(sql, j)
+				}
+
+				err := conn.SendBatch(context.Background(), batch).Close()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(bytesWritten)/float64(b.N), "written-bytes/op")
+		})
+	}
+}
+
 func BenchmarkSelectManyUnknownEnum(b *testing.B) {
 	conn := mustConnectString(b, os.Getenv("PGX_TEST_DATABASE"))
 	defer closeConn(b, conn)
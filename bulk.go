@@ -0,0 +1,192 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultKeysChunkSize is the maximum number of keys sent in a single statement by DeleteByKeys and the default for
+// CollectRowsByKeys.
+const defaultKeysChunkSize = 10000
+
+// DeleteByKeys deletes the rows of table where keyColumn is one of keys. It issues a DELETE with all keys as a single
+// array parameter. Very large key lists are split into multiple statements. The statements are not run in a
+// transaction by DeleteByKeys so db should be a Tx if the delete must be atomic. It returns the total number of rows
+// deleted.
+func DeleteByKeys[K any](
+	ctx context.Context,
+	db interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	},
+	table Identifier,
+	keyColumn string,
+	keys []K,
+) (int64, error) {
+	if len(table) == 0 {
+		return 0, errors.New("table name must not be empty")
+	}
+	for _, part := range table {
+		if part == "" {
+			return 0, errors.New("table name must not have empty parts")
+		}
+	}
+	if keyColumn == "" {
+		return 0, errors.New("key column must not be empty")
+	}
+
+	sql := "delete from " + table.Sanitize() + " where " + Identifier{keyColumn}.Sanitize() + " = any($1)"
+
+	var rowsAffected int64
+	for len(keys) > 0 {
+		chunk := keys
+		if len(chunk) > defaultKeysChunkSize {
+			chunk = chunk[:defaultKeysChunkSize]
+		}
+		keys = keys[len(chunk):]
+
+		commandTag, err := db.Exec(ctx, sql, chunk)
+		if err != nil {
+			return rowsAffected, err
+		}
+		rowsAffected += commandTag.RowsAffected()
+	}
+
+	return rowsAffected, nil
+}
+
+// CollectRowsByKeys queries db with sql once per chunk of keys and collects the rows of all queries with fn. sql must
+// take the keys as its only parameter, e.g. "select * from t where id = any($1)". At most chunkSize keys are sent per
+// query. If chunkSize is <= 0 a default of 10000 is used.
+//
+// The queries are not run in a transaction or snapshot by CollectRowsByKeys so db should be a Tx if a consistent view
+// is required. Rows are returned in the order of the queries but the order within each query is determined by sql. A
+// row may appear more than once if it matches keys in multiple chunks.
+func CollectRowsByKeys[K, T any](
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	sql string,
+	keys []K,
+	chunkSize int,
+	fn RowToFunc[T],
+) ([]T, error) {
+	if chunkSize <= 0 {
+		chunkSize = defaultKeysChunkSize
+	}
+
+	result := []T{}
+	for len(keys) > 0 {
+		chunk := keys
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+		keys = keys[len(chunk):]
+
+		rows, err := db.Query(ctx, sql, chunk)
+		if err != nil {
+			return nil, err
+		}
+		chunkResult, err := CollectRows(rows, fn)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, chunkResult...)
+	}
+
+	return result, nil
+}
+
+// CopyFromUpsert inserts or updates rows of table from rowSrc. In a transaction it creates a temporary staging table
+// with columnNames of table, copies rowSrc into it with the copy protocol, and then merges it into table with INSERT
+// ... ON CONFLICT (conflictColumns) DO UPDATE. Columns of columnNames not in conflictColumns are updated to the new
+// values. If all columns are conflict columns existing rows are left unchanged. conflictColumns must match a unique
+// index or constraint of table and rowSrc must not contain more than one row with the same conflict columns.
+//
+// The transaction is started with db.Begin, so if db is a Tx a savepoint is used instead. It returns the number of rows
+// copied and the number of rows inserted or updated.
+func CopyFromUpsert(
+	ctx context.Context,
+	db interface {
+		Begin(ctx context.Context) (Tx, error)
+	},
+	table Identifier,
+	columnNames []string,
+	conflictColumns []string,
+	rowSrc CopyFromSource,
+) (copied int64, upserted int64, err error) {
+	if len(table) == 0 {
+		return 0, 0, errors.New("table name must not be empty")
+	}
+	if len(columnNames) == 0 {
+		return 0, 0, errors.New("column names must not be empty")
+	}
+	if len(conflictColumns) == 0 {
+		return 0, 0, errors.New("conflict columns must not be empty")
+	}
+
+	isConflictColumn := make(map[string]bool, len(conflictColumns))
+	for _, column := range conflictColumns {
+		isConflictColumn[column] = true
+	}
+
+	quotedColumns := make([]string, len(columnNames))
+	var updates []string
+	for i, column := range columnNames {
+		quotedColumns[i] = Identifier{column}.Sanitize()
+		if !isConflictColumn[column] {
+			updates = append(updates, fmt.Sprintf("%[1]s = excluded.%[1]s", quotedColumns[i]))
+		}
+		delete(isConflictColumn, column)
+	}
+	for column := range isConflictColumn {
+		return 0, 0, fmt.Errorf("conflict column %q is not in column names", column)
+	}
+
+	quotedConflictColumns := make([]string, len(conflictColumns))
+	for i, column := range conflictColumns {
+		quotedConflictColumns[i] = Identifier{column}.Sanitize()
+	}
+
+	staging := Identifier{"pgx_copy_from_upsert_staging"}
+	columnList := strings.Join(quotedColumns, ", ")
+
+	mergeSQL := "insert into " + table.Sanitize() + " (" + columnList + ") select " + columnList + " from " + staging.Sanitize() +
+		" on conflict (" + strings.Join(quotedConflictColumns, ", ") + ") "
+	if len(updates) > 0 {
+		mergeSQL += "do update set " + strings.Join(updates, ", ")
+	} else {
+		mergeSQL += "do nothing"
+	}
+
+	err = BeginFunc(ctx, db, func(tx Tx) error {
+		_, err := tx.Exec(ctx, "create temporary table "+staging.Sanitize()+" on commit drop as select "+columnList+" from "+table.Sanitize()+" with no data")
+		if err != nil {
+			return err
+		}
+
+		copied, err = tx.CopyFrom(ctx, staging, columnNames, rowSrc)
+		if err != nil {
+			return err
+		}
+
+		commandTag, err := tx.Exec(ctx, mergeSQL)
+		if err != nil {
+			return err
+		}
+		upserted = commandTag.RowsAffected()
+
+		// Drop explicitly in case db is a Tx and the staging table would otherwise live until its commit.
+		_, err = tx.Exec(ctx, "drop table "+staging.Sanitize())
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return copied, upserted, nil
+}
@@ -0,0 +1,94 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteByKeys(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table delete_by_keys (id int8 primary key);
+insert into delete_by_keys (id) select n from generate_series(1, 20001) n;`)
+
+		keys := make([]int64, 0, 20002)
+		for i := int64(2); i <= 20001; i++ {
+			keys = append(keys, i)
+		}
+		keys = append(keys, 50000)
+
+		n, err := pgx.DeleteByKeys(ctx, conn, pgx.Identifier{"delete_by_keys"}, "id", keys)
+		require.NoError(t, err)
+		require.EqualValues(t, 20000, n)
+
+		rows, _ := conn.Query(ctx, "select id from delete_by_keys")
+		remaining, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+		require.NoError(t, err)
+		require.Equal(t, []int64{1}, remaining)
+
+		_, err = pgx.DeleteByKeys(ctx, conn, pgx.Identifier{"delete_by_keys"}, "", keys)
+		require.Error(t, err)
+	})
+}
+
+func TestCollectRowsByKeys(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		keys := make([]int32, 0, 25)
+		for i := int32(1); i <= 25; i++ {
+			keys = append(keys, i)
+		}
+
+		values, err := pgx.CollectRowsByKeys(ctx, conn, "select n from unnest($1::int4[]) n where n % 2 = 0 order by n", keys, 10, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24}, values)
+
+		values, err = pgx.CollectRowsByKeys(ctx, conn, "select n from unnest($1::int4[]) n", []int32{}, 0, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Empty(t, values)
+	})
+}
+
+func TestCopyFromUpsert(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_from_upsert (id int4 primary key, name text not null, updated_count int4 not null default 0);
+insert into copy_from_upsert (id, name) values (1, 'a'), (2, 'b');`)
+
+		copied, upserted, err := pgx.CopyFromUpsert(ctx, conn, pgx.Identifier{"copy_from_upsert"}, []string{"id", "name"}, []string{"id"}, pgx.CopyFromRows([][]any{
+			{int32(2), "B"},
+			{int32(3), "c"},
+		}))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, copied)
+		require.EqualValues(t, 2, upserted)
+
+		rows, _ := conn.Query(ctx, "select name from copy_from_upsert order by id")
+		names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "B", "c"}, names)
+
+		_, _, err = pgx.CopyFromUpsert(ctx, conn, pgx.Identifier{"copy_from_upsert"}, []string{"id"}, []string{"id"}, pgx.CopyFromRows([][]any{
+			{int32(3)},
+			{int32(4)},
+		}))
+		require.Error(t, err) // name is not null
+
+		copied, upserted, err = pgx.CopyFromUpsert(ctx, conn, pgx.Identifier{"copy_from_upsert"}, []string{"id", "name"}, []string{"name"}, pgx.CopyFromRows(nil))
+		require.Error(t, err) // no unique constraint on name
+		require.Zero(t, copied)
+		require.Zero(t, upserted)
+
+		_, _, err = pgx.CopyFromUpsert(ctx, conn, pgx.Identifier{"copy_from_upsert"}, []string{"id", "name"}, []string{"missing"}, pgx.CopyFromRows(nil))
+		require.ErrorContains(t, err, "not in column names")
+
+		ensureConnValid(t, conn)
+	})
+}
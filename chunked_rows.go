@@ -0,0 +1,203 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ChunkedRows is the result of QueryChunked. Rows are fetched from the server a chunk at a time by FetchMore and then
+// read with Next. This bounds the memory used for large results without needing a named cursor. ChunkedRows must be
+// closed before the *Conn can be used again.
+//
+//	rows, err := conn.QueryChunked(ctx, 1000, "select id from widgets")
+//	if err != nil {
+//		return err
+//	}
+//	defer rows.Close()
+//
+//	for rows.FetchMore() {
+//		for rows.Next() {
+//			// scan and process row
+//		}
+//	}
+//
+//	return rows.Err()
+type ChunkedRows struct {
+	conn         *Conn
+	typeMap      *pgtype.Map
+	portalReader *pgconn.PortalReader
+	values       [][]byte
+	commandTag   pgconn.CommandTag
+	err          error
+	closed       bool
+}
+
+// QueryChunked executes sql with args and returns a *ChunkedRows that fetches the result chunkSize rows at a time by
+// executing the unnamed portal with a row limit. The portal is suspended between chunks so the query runs in a single
+// implicit transaction until the ChunkedRows is closed.
+//
+// The statement is prepared or described according to the connection's DefaultQueryExecMode in the same way as Query,
+// so with the statement or description cache no extra round trip is needed once sql is cached. A portal requires the
+// extended protocol, so QueryExecModeSimpleProtocol is handled like QueryExecModeExec.
+func (c *Conn) QueryChunked(ctx context.Context, chunkSize int, sql string, args ...any) (*ChunkedRows, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("chunkSize must be greater than 0")
+	}
+	if err := checkParameterCount(len(args)); err != nil {
+		return nil, err
+	}
+
+	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
+		return nil, err
+	}
+
+	mode := c.config.DefaultQueryExecMode
+	sd, explicitPreparedStatement := c.preparedStatements[sql]
+	if sd == nil && (mode == QueryExecModeCacheStatement || mode == QueryExecModeCacheDescribe || mode == QueryExecModeDescribeExec) {
+		var err error
+		sd, err = c.getStatementDescription(ctx, mode, sql)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if sd != nil && len(sd.ParamOIDs) != len(args) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(sd.ParamOIDs), len(args))
+	}
+
+	c.eqb.reset()
+	err := c.eqb.Build(c.typeMap, sd, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr *pgconn.PortalReader
+	switch {
+	case sd == nil:
+		pr = c.pgConn.ExecParamsPortal(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats, uint32(chunkSize))
+	case !explicitPreparedStatement && mode == QueryExecModeCacheDescribe:
+		pr = c.pgConn.ExecParamsPortal(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats, uint32(chunkSize))
+	default:
+		pr = c.pgConn.ExecPreparedPortal(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats, uint32(chunkSize))
+	}
+	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
+
+	if err := pr.Err(); err != nil {
+		pr.Close()
+		return nil, err
+	}
+
+	return &ChunkedRows{conn: c, typeMap: c.typeMap, portalReader: pr}, nil
+}
+
+// FetchMore fetches the next chunk of rows. Any unread rows of the previous chunk are discarded. It returns false when
+// all rows have been fetched or an error occurred. The rows are closed automatically when FetchMore returns false.
+func (rows *ChunkedRows) FetchMore() bool {
+	if rows.closed {
+		return false
+	}
+
+	if rows.portalReader.FetchMore() {
+		return true
+	}
+
+	rows.Close()
+	return false
+}
+
+// Next prepares the next row of the current chunk for reading. It returns false when the chunk is exhausted.
+func (rows *ChunkedRows) Next() bool {
+	if rows.closed {
+		return false
+	}
+
+	if rows.portalReader.NextRow() {
+		rows.values = rows.portalReader.Values()
+		return true
+	}
+
+	rows.values = nil
+	if rows.portalReader.Err() != nil {
+		rows.Close()
+	}
+	return false
+}
+
+// FieldDescriptions returns the field descriptions of the result.
+func (rows *ChunkedRows) FieldDescriptions() []pgconn.FieldDescription {
+	return rows.portalReader.FieldDescriptions()
+}
+
+// Scan reads the values from the current row into dest values positionally.
+func (rows *ChunkedRows) Scan(dest ...any) error {
+	err := ScanRow(rows.typeMap, rows.FieldDescriptions(), rows.values, dest...)
+	if err != nil {
+		rows.fatal(err)
+	}
+	return err
+}
+
+// Values returns the decoded values of the current row.
+func (rows *ChunkedRows) Values() ([]any, error) {
+	if rows.closed {
+		return nil, errors.New("rows is closed")
+	}
+
+	values, err := decodeRowValues(rows.typeMap, rows.FieldDescriptions(), rows.values)
+	if err != nil {
+		rows.fatal(err)
+		return nil, rows.Err()
+	}
+
+	return values, nil
+}
+
+// RawValues returns the unparsed bytes of the current row. The returned data is only valid until the next Next call or
+// the ChunkedRows is closed.
+func (rows *ChunkedRows) RawValues() [][]byte {
+	return rows.values
+}
+
+// Conn returns the underlying *Conn.
+func (rows *ChunkedRows) Conn() *Conn {
+	return rows.conn
+}
+
+// Err returns any error that occurred while reading.
+func (rows *ChunkedRows) Err() error {
+	return rows.err
+}
+
+// CommandTag returns the command tag reported for the last chunk. It is only available after all rows are fetched and
+// the rows are closed.
+func (rows *ChunkedRows) CommandTag() pgconn.CommandTag {
+	return rows.commandTag
+}
+
+// Close closes the rows, discarding any remaining rows and making the connection ready for use again. It is safe to
+// call Close after rows is already closed.
+func (rows *ChunkedRows) Close() {
+	if rows.closed {
+		return
+	}
+	rows.closed = true
+	rows.values = nil
+
+	var err error
+	rows.commandTag, err = rows.portalReader.Close()
+	if rows.err == nil {
+		rows.err = err
+	}
+}
+
+func (rows *ChunkedRows) fatal(err error) {
+	if rows.err != nil {
+		return
+	}
+	rows.err = err
+	rows.Close()
+}
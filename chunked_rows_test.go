@@ -0,0 +1,126 @@
+package pgx_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnQueryChunked(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.QueryChunked(ctx, 3, "select n from generate_series(1, $1::int4) n", 10)
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var chunks [][]int32
+		for rows.FetchMore() {
+			var chunk []int32
+			for rows.Next() {
+				var n int32
+				require.NoError(t, rows.Scan(&n))
+				chunk = append(chunk, n)
+			}
+			chunks = append(chunks, chunk)
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, [][]int32{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}, {10}}, chunks)
+		require.True(t, rows.CommandTag().Select())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryChunkedUsesStatementCache(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := "select n from generate_series(1, $1::int4) n"
+
+		readAll := func() []int32 {
+			rows, err := conn.QueryChunked(ctx, 2, sql, 3)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			var values []int32
+			for rows.FetchMore() {
+				for rows.Next() {
+					var n int32
+					require.NoError(t, rows.Scan(&n))
+					values = append(values, n)
+				}
+			}
+			require.NoError(t, rows.Err())
+			return values
+		}
+
+		require.Equal(t, []int32{1, 2, 3}, readAll())
+
+		var trace bytes.Buffer
+		conn.PgConn().Frontend().Trace(&trace, pgproto3.TracerOptions{SuppressTimestamps: true})
+		values := readAll()
+		conn.PgConn().Frontend().Untrace()
+		require.Equal(t, []int32{1, 2, 3}, values)
+
+		// The cached description means the statement is not described before the portal is bound.
+		require.NotContains(t, trace.String(), "ParameterDescription")
+		if conn.Config().DefaultQueryExecMode == pgx.QueryExecModeCacheStatement {
+			require.NotContains(t, trace.String(), "Parse")
+		} else {
+			require.Equal(t, 1, strings.Count(trace.String(), "\tParse\t"))
+		}
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryChunkedCloseEarly(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.QueryChunked(ctx, 2, "select n from generate_series(1, 10) n")
+		require.NoError(t, err)
+
+		require.True(t, rows.FetchMore())
+		require.True(t, rows.Next())
+		rows.Close()
+		require.NoError(t, rows.Err())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryChunkedErrors(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.QueryChunked(ctx, 2, "select 1 from missing_table")
+		require.Error(t, err)
+		ensureConnValid(t, conn)
+
+		rows, err := conn.QueryChunked(ctx, 2, "select 1 / (3 - n) from generate_series(1, 5) n")
+		require.NoError(t, err)
+		for rows.FetchMore() {
+			for rows.Next() {
+			}
+		}
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, rows.Err(), &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
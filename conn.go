@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -33,12 +34,31 @@ type ConnConfig struct {
 	// "cache_describe" query exec mode.
 	DescriptionCacheCapacity int
 
+	// ResultCacheCapacity is the maximum number of results held in the result cache. Only queries marked with
+	// QueryCacheResult use the result cache. If 0 the result cache is disabled.
+	ResultCacheCapacity int
+
+	// ResultCacheTTL is how long a result is served from the result cache before the query is executed again. If 0
+	// results expire as soon as they are stored so the result cache has no effect.
+	ResultCacheTTL time.Duration
+
 	// DefaultQueryExecMode controls the default mode for executing queries. By default pgx uses the extended protocol
 	// and automatically prepares and caches prepared statements. However, this may be incompatible with proxies such as
 	// PGBouncer. In this case it may be preferrable to use QueryExecModeExec or QueryExecModeSimpleProtocol. The same
 	// functionality can be controlled on a per query basis by passing a QueryExecMode as the first query argument.
 	DefaultQueryExecMode QueryExecMode
 
+	// StatementNameFunc, if set, is used to generate the names of statements that are automatically prepared for the
+	// statement cache. This can make statements easier to identify in server logs and pg_prepared_statements. If the
+	// returned name is empty or already in use on the connection a numeric suffix is appended to make it unique.
+	StatementNameFunc func(sql string) string
+
+	// SessionTimeZone is the name of a time zone (e.g. America/Chicago) that is set as the TimeZone of the session when
+	// connecting. In addition, timestamptz values are scanned into time.Time in this location. timestamp values are not
+	// affected as they do not represent a specific instant in time and continue to be scanned as UTC. The name must be
+	// known to both PostgreSQL and time.LoadLocation.
+	SessionTimeZone string
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -66,8 +86,10 @@ type Conn struct {
 	pgConn             *pgconn.PgConn
 	config             *ConnConfig // config used when establishing this connection
 	preparedStatements map[string]*pgconn.StatementDescription
+	statementNames     map[string]struct{} // names generated for the statement cache that may still exist on the server
 	statementCache     stmtcache.Cache
 	descriptionCache   stmtcache.Cache
+	resultCache        *resultCache
 
 	queryTracer    QueryTracer
 	batchTracer    BatchTracer
@@ -102,6 +124,17 @@ func (ident Identifier) Sanitize() string {
 // ErrNoRows occurs when rows are expected but none are returned.
 var ErrNoRows = errors.New("no rows in result set")
 
+// ErrTooManyRows occurs when a query returns more rows than allowed by QueryMaxRows.
+var ErrTooManyRows = errors.New("too many rows in result set")
+
+// MaxQueryParameters is the maximum number of parameters PostgreSQL accepts for a single query sent with the extended
+// protocol. Use an array parameter (e.g. "= any($1)") instead of a long IN list. CollectRowsByKeys splits very large
+// arrays across multiple queries.
+const MaxQueryParameters = 65535
+
+// ErrTooManyParameters occurs when a query has more than MaxQueryParameters arguments.
+var ErrTooManyParameters = errors.New("too many query parameters")
+
 var errDisabledStatementCache = fmt.Errorf("cannot use QueryExecModeCacheStatement with disabled statement cache")
 var errDisabledDescriptionCache = fmt.Errorf("cannot use QueryExecModeCacheDescribe with disabled description cache")
 
@@ -233,6 +266,19 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 		queryTracer: config.Tracer,
 	}
 
+	if config.SessionTimeZone != "" {
+		loc, err := time.LoadLocation(config.SessionTimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SessionTimeZone: %w", err)
+		}
+		config.RuntimeParams["timezone"] = config.SessionTimeZone
+
+		// Replace the codec in place so the array, range, and multirange types built on timestamptz also use it.
+		if t, ok := c.typeMap.TypeForOID(pgtype.TimestamptzOID); ok {
+			t.Codec = pgtype.TimestamptzCodec{ScanLocation: loc}
+		}
+	}
+
 	if t, ok := c.queryTracer.(BatchTracer); ok {
 		c.batchTracer = t
 	}
@@ -254,6 +300,7 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 	}
 
 	c.preparedStatements = make(map[string]*pgconn.StatementDescription)
+	c.statementNames = make(map[string]struct{})
 	c.doneChan = make(chan struct{})
 	c.closedChan = make(chan error)
 	c.wbuf = make([]byte, 0, 1024)
@@ -266,6 +313,10 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 		c.descriptionCache = stmtcache.NewLRUCache(c.config.DescriptionCacheCapacity)
 	}
 
+	if c.config.ResultCacheCapacity > 0 {
+		c.resultCache = newResultCache(c.config.ResultCacheCapacity, c.config.ResultCacheTTL)
+	}
+
 	return c, nil
 }
 
@@ -319,6 +370,58 @@ func (c *Conn) Prepare(ctx context.Context, name, sql string) (sd *pgconn.Statem
 	return sd, nil
 }
 
+// PrepareAll prepares statements, a map of statement names to SQL, in a single round trip. It is like calling Prepare
+// for each statement except that all statements are sent together in a pipeline. Statements that are already prepared
+// with the same SQL are skipped. If a statement cannot be prepared the returned error names it. Statements prepared
+// before the failure remain prepared.
+func (c *Conn) PrepareAll(ctx context.Context, statements map[string]string) error {
+	names := make([]string, 0, len(statements))
+	for name, sql := range statements {
+		if sd, ok := c.preparedStatements[name]; ok && sd.SQL == sql {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	pipeline := c.pgConn.StartPipeline(ctx)
+	for _, name := range names {
+		pipeline.SendPrepare(name, statements[name], nil)
+	}
+
+	err := pipeline.Sync()
+	if err != nil {
+		pipeline.Close()
+		return err
+	}
+
+	for _, name := range names {
+		results, err := pipeline.GetResults()
+		if err != nil {
+			pipeline.Close()
+			return fmt.Errorf("failed to prepare statement %s: %w", name, err)
+		}
+
+		resultSD, ok := results.(*pgconn.StatementDescription)
+		if !ok {
+			pipeline.Close()
+			return fmt.Errorf("expected statement description, got %T", results)
+		}
+
+		c.preparedStatements[name] = &pgconn.StatementDescription{
+			Name:      name,
+			SQL:       statements[name],
+			ParamOIDs: resultSD.ParamOIDs,
+			Fields:    resultSD.Fields,
+		}
+	}
+
+	return pipeline.Close()
+}
+
 // Deallocate released a prepared statement
 func (c *Conn) Deallocate(ctx context.Context, name string) error {
 	delete(c.preparedStatements, name)
@@ -326,9 +429,69 @@ func (c *Conn) Deallocate(ctx context.Context, name string) error {
 	return err
 }
 
+// RefreshPreparedStatement deallocates and prepares the prepared statement name again with the same SQL. This updates
+// the parameter and result descriptions of the statement after the schema it depends on has changed. The
+// *pgconn.StatementDescription previously returned by Prepare is updated in place. If the statement cannot be prepared
+// again it is no longer prepared on the connection and the error is returned.
+func (c *Conn) RefreshPreparedStatement(ctx context.Context, name string) error {
+	sd, ok := c.preparedStatements[name]
+	if !ok {
+		return fmt.Errorf("prepared statement %q does not exist", name)
+	}
+
+	err := c.Deallocate(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	newSD, err := c.Prepare(ctx, name, sd.SQL)
+	if err != nil {
+		return err
+	}
+
+	sd.ParamOIDs = newSD.ParamOIDs
+	sd.Fields = newSD.Fields
+	c.preparedStatements[name] = sd
+
+	return nil
+}
+
+// PreparedStatementInfo describes a statement prepared on a connection.
+type PreparedStatementInfo struct {
+	Name      string
+	SQL       string
+	ParamOIDs []uint32
+	Fields    []pgconn.FieldDescription
+
+	// Cached is true if the statement was automatically prepared by the statement cache of QueryExecModeCacheStatement
+	// rather than with Prepare.
+	Cached bool
+}
+
+// PreparedStatements returns the statements prepared on the connection sorted by name. This includes both statements
+// prepared with Prepare and statements automatically prepared by the statement cache.
+func (c *Conn) PreparedStatements() []PreparedStatementInfo {
+	infos := make([]PreparedStatementInfo, 0, len(c.preparedStatements))
+	for name, sd := range c.preparedStatements {
+		_, cached := c.statementNames[name]
+		infos = append(infos, PreparedStatementInfo{
+			Name:      name,
+			SQL:       sd.SQL,
+			ParamOIDs: append([]uint32(nil), sd.ParamOIDs...),
+			Fields:    append([]pgconn.FieldDescription(nil), sd.Fields...),
+			Cached:    cached,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	return infos
+}
+
 // DeallocateAll releases all previously prepared statements from the server and client, where it also resets the statement and description cache.
 func (c *Conn) DeallocateAll(ctx context.Context) error {
 	c.preparedStatements = map[string]*pgconn.StatementDescription{}
+	c.statementNames = map[string]struct{}{}
 	if c.config.StatementCacheCapacity > 0 {
 		c.statementCache = stmtcache.NewLRUCache(c.config.StatementCacheCapacity)
 	}
@@ -339,6 +502,29 @@ func (c *Conn) DeallocateAll(ctx context.Context) error {
 	return err
 }
 
+// nextStatementName returns the name for a statement for sql that is being automatically prepared.
+func (c *Conn) nextStatementName(sql string) string {
+	if c.config.StatementNameFunc == nil {
+		name := stmtcache.NextStatementName()
+		c.statementNames[name] = struct{}{}
+		return name
+	}
+
+	baseName := c.config.StatementNameFunc(sql)
+	name := baseName
+	for i := 1; ; i++ {
+		_, generated := c.statementNames[name]
+		_, prepared := c.preparedStatements[name]
+		if name != "" && !generated && !prepared {
+			break
+		}
+		name = baseName + "_" + strconv.Itoa(i)
+	}
+	c.statementNames[name] = struct{}{}
+
+	return name
+}
+
 func (c *Conn) bufferNotifications(_ *pgconn.PgConn, n *pgconn.Notification) {
 	c.notifications = append(c.notifications, n)
 }
@@ -368,6 +554,18 @@ func (c *Conn) IsClosed() bool {
 	return c.pgConn.IsClosed()
 }
 
+// TxStatus returns the transaction status of the connection as last reported by the server: 'I' if idle (not in a
+// transaction), 'T' if in a transaction, or 'E' if in a failed transaction.
+func (c *Conn) TxStatus() byte {
+	return c.pgConn.TxStatus()
+}
+
+// InTransaction reports if the connection is in a transaction or a failed transaction.
+func (c *Conn) InTransaction() bool {
+	txStatus := c.pgConn.TxStatus()
+	return txStatus == 'T' || txStatus == 'E'
+}
+
 func (c *Conn) die(err error) {
 	if c.IsClosed() {
 		return
@@ -389,6 +587,14 @@ func (c *Conn) Ping(ctx context.Context) error {
 	return err
 }
 
+// CancelRequest sends a cancel request to the PostgreSQL server for the query currently in progress on c. Unlike
+// other methods, it is safe to call CancelRequest from a different goroutine than the one using c. It returns an error
+// if unable to deliver the cancel request, but lack of an error does not ensure that the query was canceled. The
+// connection remains usable after the canceled query returns.
+func (c *Conn) CancelRequest(ctx context.Context) error {
+	return c.pgConn.CancelRequest(ctx)
+}
+
 // PgConn returns the underlying *pgconn.PgConn. This is an escape hatch method that allows lower level access to the
 // PostgreSQL connection than pgx exposes.
 //
@@ -396,6 +602,33 @@ func (c *Conn) Ping(ctx context.Context) error {
 // is used and the connection must be returned to the same state before any *pgx.Conn methods are again used.
 func (c *Conn) PgConn() *pgconn.PgConn { return c.pgConn }
 
+// WithPgConn calls f with the underlying *pgconn.PgConn. It is a safer alternative to PgConn for operations pgx does
+// not wrap. The connection must be idle when WithPgConn is called. If f leaves the connection busy (e.g. a result
+// was not fully read) the connection is closed and an error is returned.
+func (c *Conn) WithPgConn(ctx context.Context, f func(*pgconn.PgConn) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.IsClosed() {
+		return errors.New("conn closed")
+	}
+	if c.pgConn.IsBusy() {
+		return errors.New("conn busy")
+	}
+
+	err := f(c.pgConn)
+
+	if c.pgConn.IsBusy() {
+		busyErr := errors.New("WithPgConn function left conn busy")
+		c.die(busyErr)
+		if err == nil {
+			err = busyErr
+		}
+	}
+
+	return err
+}
+
 // TypeMap returns the connection info used for this connection.
 func (c *Conn) TypeMap() *pgtype.Map { return c.typeMap }
 
@@ -422,6 +655,14 @@ func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 	return commandTag, err
 }
 
+// checkParameterCount returns an error if n arguments cannot be sent with the extended protocol.
+func checkParameterCount(n int) error {
+	if n > MaxQueryParameters {
+		return fmt.Errorf("%w: got %d, PostgreSQL allows at most %d", ErrTooManyParameters, n, MaxQueryParameters)
+	}
+	return nil
+}
+
 func (c *Conn) exec(ctx context.Context, sql string, arguments ...any) (commandTag pgconn.CommandTag, err error) {
 	mode := c.config.DefaultQueryExecMode
 	var queryRewriter QueryRewriter
@@ -435,6 +676,8 @@ optionLoop:
 		case QueryRewriter:
 			queryRewriter = arg
 			arguments = arguments[1:]
+		case QueryIdempotent:
+			arguments = arguments[1:]
 		default:
 			break optionLoop
 		}
@@ -452,6 +695,12 @@ optionLoop:
 		mode = QueryExecModeSimpleProtocol
 	}
 
+	if mode != QueryExecModeSimpleProtocol {
+		if err := checkParameterCount(len(arguments)); err != nil {
+			return pgconn.CommandTag{}, err
+		}
+	}
+
 	if sd, ok := c.preparedStatements[sql]; ok {
 		return c.execPrepared(ctx, sd, arguments)
 	}
@@ -463,7 +712,7 @@ optionLoop:
 		}
 		sd := c.statementCache.Get(sql)
 		if sd == nil {
-			sd, err = c.Prepare(ctx, stmtcache.NextStatementName(), sql)
+			sd, err = c.Prepare(ctx, c.nextStatementName(sql), sql)
 			if err != nil {
 				return pgconn.CommandTag{}, err
 			}
@@ -635,6 +884,26 @@ type QueryResultFormats []int16
 // QueryResultFormatsByOID controls the result format (text=0, binary=1) of a query by the result column OID.
 type QueryResultFormatsByOID map[uint32]int16
 
+// QueryResultFields supplies the expected result fields of a query. The server is not asked to describe the result.
+// Instead, the rows are decoded according to the DataTypeOID and Format of each field and results are requested in the
+// supplied formats.
+//
+// Prepared statements and statements described by the QueryExecMode use the statement description for the parameters
+// and the query fails if the result fields of the description do not match the supplied OIDs. QueryExecModeExec has no
+// statement description, so the server still describes the result and the query fails when the first result arrives if
+// its field count or OIDs do not match. It has no effect with QueryExecModeSimpleProtocol.
+type QueryResultFields []pgconn.FieldDescription
+
+// QueryMaxRows limits the number of rows a query may return. If the query returns more rows the rows are closed and
+// Err returns an error that wraps ErrTooManyRows. Closing the rows reads and discards the remaining result from the
+// server. A value <= 0 means no limit.
+type QueryMaxRows int
+
+// QueryIdempotent marks a query as safe to run more than once. It is used by pgxpool.Pool to decide whether a failed
+// query may be retried. Conn.Exec and Conn.Query accept and ignore it so the same arguments can be used with a
+// connection acquired from a pool. Like the other query options it must precede the query arguments.
+type QueryIdempotent struct{}
+
 // QueryRewriter rewrites a query when used as the first arguments to a query method.
 type QueryRewriter interface {
 	RewriteQuery(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error)
@@ -655,9 +924,10 @@ type QueryRewriter interface {
 // An implementor of QueryRewriter may be passed as the first element of args. It can rewrite the sql and change or
 // replace args. For example, NamedArgs is QueryRewriter that implements named arguments.
 //
-// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats, and
-// QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
-// needed. See the documentation for those types for details.
+// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats,
+// QueryResultFormatsByOID, QueryResultFields, QueryCacheResult, QueryMaxRows, and QueryIdempotent may be used as the
+// first args to control exactly how the query is executed. This is rarely needed. See the documentation for those types
+// for details.
 func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
 	if c.queryTracer != nil {
 		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
@@ -672,6 +942,9 @@ func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error)
 
 	var resultFormats QueryResultFormats
 	var resultFormatsByOID QueryResultFormatsByOID
+	var resultFields QueryResultFields
+	var cacheResult bool
+	var maxRows QueryMaxRows
 	mode := c.config.DefaultQueryExecMode
 	var queryRewriter QueryRewriter
 
@@ -684,12 +957,23 @@ optionLoop:
 		case QueryResultFormatsByOID:
 			resultFormatsByOID = arg
 			args = args[1:]
+		case QueryResultFields:
+			resultFields = arg
+			args = args[1:]
+		case QueryCacheResult:
+			cacheResult = true
+			args = args[1:]
+		case QueryMaxRows:
+			maxRows = arg
+			args = args[1:]
 		case QueryExecMode:
 			mode = arg
 			args = args[1:]
 		case QueryRewriter:
 			queryRewriter = arg
 			args = args[1:]
+		case QueryIdempotent:
+			args = args[1:]
 		default:
 			break optionLoop
 		}
@@ -716,8 +1000,34 @@ optionLoop:
 	c.eqb.reset()
 	anynil.NormalizeSlice(args)
 	rows := c.getRows(ctx, sql, args)
+	rows.maxRows = int(maxRows)
+
+	if mode != QueryExecModeSimpleProtocol {
+		if err := checkParameterCount(len(args)); err != nil {
+			rows.fatal(err)
+			return rows, err
+		}
+	}
+
+	if cacheResult && c.resultCache != nil && resultFormats == nil && resultFormatsByOID == nil && resultFields == nil {
+		if key, ok := c.resultCacheKey(sql, args); ok {
+			if result := c.resultCache.get(key); result != nil {
+				rows.cacheHit = result
+				rows.commandTag = result.commandTag
+				return rows, nil
+			}
+			rows.cacheRecord = &cachedResult{key: key}
+		}
+	}
 
 	var err error
+	if resultFields != nil {
+		resultFormats = make([]int16, len(resultFields))
+		for i := range resultFields {
+			resultFormats[i] = resultFields[i].Format
+		}
+	}
+
 	sd, explicitPreparedStatement := c.preparedStatements[sql]
 	if sd != nil || mode == QueryExecModeCacheStatement || mode == QueryExecModeCacheDescribe || mode == QueryExecModeDescribeExec {
 		if sd == nil {
@@ -733,6 +1043,14 @@ optionLoop:
 			return rows, rows.err
 		}
 
+		if resultFields != nil {
+			err = checkResultFields(sd.Fields, resultFields)
+			if err != nil {
+				rows.fatal(err)
+				return rows, rows.err
+			}
+		}
+
 		rows.sql = sd.SQL
 
 		err = c.eqb.Build(c.typeMap, sd, args)
@@ -741,7 +1059,7 @@ optionLoop:
 			return rows, rows.err
 		}
 
-		if resultFormatsByOID != nil {
+		if resultFormatsByOID != nil && resultFields == nil {
 			resultFormats = make([]int16, len(sd.Fields))
 			for i := range resultFormats {
 				resultFormats[i] = resultFormatsByOID[uint32(sd.Fields[i].DataTypeOID)]
@@ -752,9 +1070,14 @@ optionLoop:
 			resultFormats = c.eqb.ResultFormats
 		}
 
-		if !explicitPreparedStatement && mode == QueryExecModeCacheDescribe {
+		switch {
+		case resultFields != nil && !explicitPreparedStatement && mode == QueryExecModeCacheDescribe:
+			rows.resultReader = c.pgConn.ExecParamsWithFields(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats, resultFields)
+		case resultFields != nil:
+			rows.resultReader = c.pgConn.ExecPreparedWithFields(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats, resultFields)
+		case !explicitPreparedStatement && mode == QueryExecModeCacheDescribe:
 			rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats)
-		} else {
+		default:
 			rows.resultReader = c.pgConn.ExecPrepared(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats)
 		}
 	} else if mode == QueryExecModeExec {
@@ -764,7 +1087,22 @@ optionLoop:
 			return rows, rows.err
 		}
 
-		rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		if resultFields != nil {
+			// There is no statement description to check the supplied fields against, so the result is described and
+			// checked when it arrives.
+			rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, resultFormats)
+			err = checkResultFields(rows.resultReader.FieldDescriptions(), resultFields)
+			if err != nil {
+				// Prefer the query error if the result has no description because the query failed.
+				if _, closeErr := rows.resultReader.Close(); closeErr != nil {
+					err = closeErr
+				}
+				rows.fatal(err)
+				return rows, rows.err
+			}
+		} else {
+			rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		}
 	} else if mode == QueryExecModeSimpleProtocol {
 		sql, err = c.sanitizeForSimpleQuery(sql, args...)
 		if err != nil {
@@ -794,6 +1132,21 @@ optionLoop:
 	return rows, rows.err
 }
 
+// checkResultFields returns an error if the result fields of a statement description do not match the expected fields.
+func checkResultFields(actual []pgconn.FieldDescription, expected QueryResultFields) error {
+	if len(actual) != len(expected) {
+		return fmt.Errorf("expected %d result fields, got %d", len(expected), len(actual))
+	}
+
+	for i := range actual {
+		if actual[i].DataTypeOID != expected[i].DataTypeOID {
+			return fmt.Errorf("expected result field %d to have type OID %d, got %d", i, expected[i].DataTypeOID, actual[i].DataTypeOID)
+		}
+	}
+
+	return nil
+}
+
 // getStatementDescription returns the statement description of the sql query
 // according to the given mode.
 //
@@ -812,7 +1165,7 @@ func (c *Conn) getStatementDescription(
 		}
 		sd = c.statementCache.Get(sql)
 		if sd == nil {
-			sd, err = c.Prepare(ctx, stmtcache.NextStatementName(), sql)
+			sd, err = c.Prepare(ctx, c.nextStatementName(sql), sql)
 			if err != nil {
 				return nil, err
 			}
@@ -848,6 +1201,8 @@ func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) Row {
 // explicit transaction control statements are executed. The returned BatchResults must be closed before the connection
 // is used again.
 func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
+	defer func() { b.results = br.(interface{ isClosed() bool }) }()
+
 	if c.batchTracer != nil {
 		ctx = c.batchTracer.TraceBatchStart(ctx, c, TraceBatchStartData{Batch: b})
 		defer func() {
@@ -864,7 +1219,7 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 
 	mode := c.config.DefaultQueryExecMode
 
-	for _, bi := range b.queuedQueries {
+	for i, bi := range b.queuedQueries {
 		var queryRewriter QueryRewriter
 		sql := bi.query
 		arguments := bi.arguments
@@ -888,16 +1243,37 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 			}
 		}
 
+		// Each query must produce exactly one result. Otherwise the results of the remaining queries would be read
+		// for the wrong queries.
+		if sanitize.MultipleStatements(sql) {
+			return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("batch query %d contains multiple statements: queue each statement separately", i)}
+		}
+
 		bi.query = sql
 		bi.arguments = arguments
 	}
 
+	if b.IsolateErrors && (mode == QueryExecModeSimpleProtocol || mode == QueryExecModeExec) {
+		return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("IsolateErrors cannot be used with %v", mode)}
+	}
+
+	if mode == QueryExecModeSimpleProtocol || mode == QueryExecModeExec {
+		for _, bi := range b.queuedQueries {
+			if bi.copyFrom != nil {
+				return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("QueueCopyFrom cannot be used with %v", mode)}
+			}
+		}
+	}
+
 	if mode == QueryExecModeSimpleProtocol {
 		return c.sendBatchQueryExecModeSimpleProtocol(ctx, b)
 	}
 
 	// All other modes use extended protocol and thus can use prepared statements.
 	for _, bi := range b.queuedQueries {
+		if err := checkParameterCount(len(bi.arguments)); err != nil {
+			return &batchResults{ctx: ctx, conn: c, err: err}
+		}
 		if sd, ok := c.preparedStatements[bi.query]; ok {
 			bi.sd = sd
 		}
@@ -917,6 +1293,52 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 	}
 }
 
+// SendBatchSimple sends all queued queries of b to the server in a single simple protocol Query message and waits for
+// them to complete. It is intended for batches of DDL statements such as migration scripts, which have no parameters
+// and may include statements that cannot be prepared. The queued queries must not have arguments and must not be
+// queued with QueueCopyFrom. Their results are discarded, so they also must not have a function set with
+// QueuedQuery.Query, QueuedQuery.QueryRow, QueuedQuery.ForEachRow, or QueuedQuery.Exec.
+//
+// When b has more than one query, PostgreSQL runs them in an implicit transaction: if any query fails none of the
+// changes are applied. Statements that cannot run inside a transaction block, such as CREATE INDEX CONCURRENTLY or
+// ALTER TYPE ... ADD VALUE before PostgreSQL 12, fail unless they are the only query in b.
+func (c *Conn) SendBatchSimple(ctx context.Context, b *Batch) (err error) {
+	if c.batchTracer != nil {
+		ctx = c.batchTracer.TraceBatchStart(ctx, c, TraceBatchStartData{Batch: b})
+		defer func() {
+			c.batchTracer.TraceBatchEnd(ctx, c, TraceBatchEndData{Err: err})
+		}()
+	}
+
+	var sb strings.Builder
+	for i, bi := range b.queuedQueries {
+		if len(bi.arguments) > 0 {
+			return fmt.Errorf("SendBatchSimple: query %d has arguments", i)
+		}
+		if bi.copyFrom != nil {
+			return fmt.Errorf("SendBatchSimple: query %d is a copy", i)
+		}
+		if bi.fn != nil {
+			return fmt.Errorf("SendBatchSimple: query %d has a result function", i)
+		}
+		if i > 0 {
+			// The newline ends a trailing line comment in the previous query.
+			sb.WriteString("\n;")
+		}
+		sb.WriteString(bi.query)
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+
+	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
+		return err
+	}
+
+	_, err = c.pgConn.Exec(ctx, sb.String()).ReadAll()
+	return err
+}
+
 func (c *Conn) sendBatchQueryExecModeSimpleProtocol(ctx context.Context, b *Batch) *batchResults {
 	var sb strings.Builder
 	for i, bi := range b.queuedQueries {
@@ -982,17 +1404,18 @@ func (c *Conn) sendBatchQueryExecModeCacheStatement(ctx context.Context, b *Batc
 	distinctNewQueriesIdxMap := make(map[string]int)
 
 	for _, bi := range b.queuedQueries {
-		if bi.sd == nil {
-			sd := c.statementCache.Get(bi.query)
+		if bi.sd == nil && !bi.inferTypes {
+			sql := bi.describeSQL()
+			sd := c.statementCache.Get(sql)
 			if sd != nil {
 				bi.sd = sd
 			} else {
-				if idx, present := distinctNewQueriesIdxMap[bi.query]; present {
+				if idx, present := distinctNewQueriesIdxMap[sql]; present {
 					bi.sd = distinctNewQueries[idx]
 				} else {
 					sd = &pgconn.StatementDescription{
-						Name: stmtcache.NextStatementName(),
-						SQL:  bi.query,
+						Name: c.nextStatementName(sql),
+						SQL:  sql,
 					}
 					distinctNewQueriesIdxMap[sd.SQL] = len(distinctNewQueries)
 					distinctNewQueries = append(distinctNewQueries, sd)
@@ -1014,16 +1437,17 @@ func (c *Conn) sendBatchQueryExecModeCacheDescribe(ctx context.Context, b *Batch
 	distinctNewQueriesIdxMap := make(map[string]int)
 
 	for _, bi := range b.queuedQueries {
-		if bi.sd == nil {
-			sd := c.descriptionCache.Get(bi.query)
+		if bi.sd == nil && !bi.inferTypes {
+			sql := bi.describeSQL()
+			sd := c.descriptionCache.Get(sql)
 			if sd != nil {
 				bi.sd = sd
 			} else {
-				if idx, present := distinctNewQueriesIdxMap[bi.query]; present {
+				if idx, present := distinctNewQueriesIdxMap[sql]; present {
 					bi.sd = distinctNewQueries[idx]
 				} else {
 					sd = &pgconn.StatementDescription{
-						SQL: bi.query,
+						SQL: sql,
 					}
 					distinctNewQueriesIdxMap[sd.SQL] = len(distinctNewQueries)
 					distinctNewQueries = append(distinctNewQueries, sd)
@@ -1041,12 +1465,13 @@ func (c *Conn) sendBatchQueryExecModeDescribeExec(ctx context.Context, b *Batch)
 	distinctNewQueriesIdxMap := make(map[string]int)
 
 	for _, bi := range b.queuedQueries {
-		if bi.sd == nil {
-			if idx, present := distinctNewQueriesIdxMap[bi.query]; present {
+		if bi.sd == nil && !bi.inferTypes {
+			sql := bi.describeSQL()
+			if idx, present := distinctNewQueriesIdxMap[sql]; present {
 				bi.sd = distinctNewQueries[idx]
 			} else {
 				sd := &pgconn.StatementDescription{
-					SQL: bi.query,
+					SQL: sql,
 				}
 				distinctNewQueriesIdxMap[sd.SQL] = len(distinctNewQueries)
 				distinctNewQueries = append(distinctNewQueries, sd)
@@ -1059,7 +1484,7 @@ func (c *Conn) sendBatchQueryExecModeDescribeExec(ctx context.Context, b *Batch)
 }
 
 func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, distinctNewQueries []*pgconn.StatementDescription, sdCache stmtcache.Cache) (pbr *pipelineBatchResults) {
-	pipeline := c.pgConn.StartPipeline(context.Background())
+	pipeline := c.pgConn.StartPipeline(ctx)
 	defer func() {
 		if pbr.err != nil {
 			pipeline.Close()
@@ -1111,32 +1536,101 @@ func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, d
 		}
 	}
 
-	// Queue the queries.
-	for _, bi := range b.queuedQueries {
-		err := c.eqb.Build(c.typeMap, bi.sd, bi.arguments)
-		if err != nil {
-			// we wrap the error so we the user can understand which query failed inside the batch
-			err = fmt.Errorf("error building query %s: %w", bi.query, err)
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+	// When errors are isolated each query is followed by a sync. In a transaction each query is also preceded by a
+	// savepoint. The savepoint is created before the first query and rolled back to before each following query. This
+	// is a no-op if the previous query succeeded and recovers the aborted transaction if it failed. The savepoint is
+	// recreated after each successful query.
+	savepoints := b.IsolateErrors && len(b.queuedQueries) > 0 && c.pgConn.TxStatus() == 'T'
+
+	// Queue the queries. pendingBytes approximates the size of the messages that have not been written yet.
+	pendingBytes := 0
+	for i, bi := range b.queuedQueries {
+		var copyData []byte
+		if bi.copyFrom != nil {
+			bi.copyFrom.conn = c
+			var err error
+			copyData, err = bi.copyFrom.buildCopyData(bi.sd)
+			if err != nil {
+				err = fmt.Errorf("error building copy data for %s: %w", bi.copyFrom.tableName.Sanitize(), err)
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+			}
+		} else {
+			err := c.eqb.Build(c.typeMap, bi.sd, bi.arguments)
+			if err != nil {
+				// we wrap the error so we the user can understand which query failed inside the batch
+				err = fmt.Errorf("error building query %s: %w", bi.query, err)
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+			}
 		}
 
-		if bi.sd.Name == "" {
+		if savepoints {
+			if i == 0 {
+				pipeline.SendQueryParams("savepoint pgx_batch_item", nil, nil, nil, nil)
+			} else {
+				pipeline.SendQueryParams("rollback to savepoint pgx_batch_item", nil, nil, nil, nil)
+			}
+		}
+
+		if bi.copyFrom != nil {
+			pipeline.SendCopyFrom(bi.query, copyData)
+			pendingBytes += len(bi.query) + len(copyData)
+		} else if bi.sd == nil {
+			pipeline.SendQueryParams(bi.query, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+			pendingBytes += len(bi.query)
+		} else if bi.sd.Name == "" {
 			pipeline.SendQueryParams(bi.sd.SQL, c.eqb.ParamValues, bi.sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats)
+			pendingBytes += len(bi.sd.SQL)
 		} else {
 			pipeline.SendQueryPrepared(bi.sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats)
+			pendingBytes += len(bi.sd.Name)
+		}
+		if bi.copyFrom == nil {
+			for _, v := range c.eqb.ParamValues {
+				pendingBytes += len(v)
+			}
+		}
+
+		if b.IsolateErrors {
+			if savepoints {
+				pipeline.SendQueryParams("release savepoint pgx_batch_item", nil, nil, nil, nil)
+				pipeline.SendQueryParams("savepoint pgx_batch_item", nil, nil, nil, nil)
+			}
+
+			err := pipeline.Sync()
+			if err != nil {
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+			}
+			pendingBytes = 0
+		}
+
+		if b.MaxBufferedBytes > 0 && pendingBytes >= b.MaxBufferedBytes {
+			err := pipeline.Flush()
+			if err != nil {
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+			}
+			pendingBytes = 0
 		}
 	}
 
-	err := pipeline.Sync()
-	if err != nil {
-		return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+	if savepoints {
+		pipeline.SendQueryParams("rollback to savepoint pgx_batch_item", nil, nil, nil, nil)
+		pipeline.SendQueryParams("release savepoint pgx_batch_item", nil, nil, nil, nil)
+	}
+
+	if !b.IsolateErrors || savepoints {
+		err := pipeline.Sync()
+		if err != nil {
+			return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+		}
 	}
 
 	return &pipelineBatchResults{
-		ctx:      ctx,
-		conn:     c,
-		pipeline: pipeline,
-		b:        b,
+		ctx:           ctx,
+		conn:          c,
+		pipeline:      pipeline,
+		b:             b,
+		isolateErrors: b.IsolateErrors,
+		savepoints:    savepoints,
 	}
 }
 
@@ -1331,6 +1825,11 @@ func (c *Conn) deallocateInvalidatedCachedStatements(ctx context.Context) error
 		return fmt.Errorf("failed to deallocate cached statement(s): %w", err)
 	}
 
+	for _, sd := range invalidatedStatements {
+		delete(c.preparedStatements, sd.Name)
+		delete(c.statementNames, sd.Name)
+	}
+
 	err = pipeline.Close()
 	if err != nil {
 		return fmt.Errorf("failed to deallocate cached statement(s): %w", err)
@@ -3,6 +3,7 @@ package pgx_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"strings"
 	"sync"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/assert"
@@ -266,6 +268,41 @@ func TestExecFailure(t *testing.T) {
 	})
 }
 
+func TestConnTooManyParameters(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		args := make([]any, pgx.MaxQueryParameters+1)
+		placeholders := make([]string, len(args))
+		for i := range args {
+			args[i] = i
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+		sql := "select 1 where 1 in (" + strings.Join(placeholders, ",") + ")"
+
+		_, err := conn.Exec(ctx, sql, args...)
+		require.ErrorIs(t, err, pgx.ErrTooManyParameters)
+
+		rows, err := conn.Query(ctx, sql, args...)
+		require.ErrorIs(t, err, pgx.ErrTooManyParameters)
+		rows.Close()
+
+		batch := &pgx.Batch{}
+		batch.Queue(sql, args...)
+		err = conn.SendBatch(ctx, batch).Close()
+		require.ErrorIs(t, err, pgx.ErrTooManyParameters)
+
+		ensureConnValid(t, conn)
+	})
+}
+
 func TestExecFailureWithArguments(t *testing.T) {
 	t.Parallel()
 
@@ -473,6 +510,123 @@ func TestPrepareIdempotency(t *testing.T) {
 	}
 }
 
+func TestRefreshPreparedStatement(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, "create temporary table refresh_ps (a int4)")
+
+	sd, err := conn.Prepare(context.Background(), "ps", "select * from refresh_ps")
+	require.NoError(t, err)
+	require.Len(t, sd.Fields, 1)
+
+	mustExec(t, conn, "alter table refresh_ps add column b text")
+
+	err = conn.RefreshPreparedStatement(context.Background(), "ps")
+	require.NoError(t, err)
+	require.Len(t, sd.Fields, 2)
+	require.Equal(t, "b", sd.Fields[1].Name)
+
+	rows, err := conn.Query(context.Background(), "ps")
+	require.NoError(t, err)
+	require.Len(t, rows.FieldDescriptions(), 2)
+	rows.Close()
+	require.NoError(t, rows.Err())
+
+	err = conn.RefreshPreparedStatement(context.Background(), "missing")
+	require.Error(t, err)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnPreparedStatements(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	require.Empty(t, conn.PreparedStatements())
+
+	_, err := conn.Prepare(context.Background(), "ps_b", "select $1::text as t")
+	require.NoError(t, err)
+	_, err = conn.Prepare(context.Background(), "ps_a", "select $1::int4 as n, $2::bool as b")
+	require.NoError(t, err)
+
+	// Statements prepared by the statement cache are included and marked as cached.
+	_, err = conn.Exec(context.Background(), "select $1::int8", pgx.QueryExecModeCacheStatement, 1)
+	require.NoError(t, err)
+
+	infos := conn.PreparedStatements()
+	require.Len(t, infos, 3)
+
+	var cachedInfo pgx.PreparedStatementInfo
+	infos, cachedInfo = infos[:2], infos[2]
+	require.True(t, strings.HasPrefix(cachedInfo.Name, "stmtcache_"))
+	require.Equal(t, "select $1::int8", cachedInfo.SQL)
+	require.Equal(t, []uint32{pgtype.Int8OID}, cachedInfo.ParamOIDs)
+	require.True(t, cachedInfo.Cached)
+
+	require.Equal(t, "ps_a", infos[0].Name)
+	require.False(t, infos[0].Cached)
+	require.Equal(t, "select $1::int4 as n, $2::bool as b", infos[0].SQL)
+	require.Equal(t, []uint32{pgtype.Int4OID, pgtype.BoolOID}, infos[0].ParamOIDs)
+	require.Len(t, infos[0].Fields, 2)
+	require.Equal(t, "n", infos[0].Fields[0].Name)
+
+	require.Equal(t, "ps_b", infos[1].Name)
+	require.Equal(t, []uint32{pgtype.TextOID}, infos[1].ParamOIDs)
+
+	require.False(t, infos[1].Cached)
+
+	err = conn.Deallocate(context.Background(), "ps_b")
+	require.NoError(t, err)
+	infos = conn.PreparedStatements()
+	require.Len(t, infos, 2)
+	require.Equal(t, "ps_a", infos[0].Name)
+}
+
+func TestConnPrepareAll(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	statements := map[string]string{
+		"ps_add":    "select $1::int4 + 1",
+		"ps_concat": "select $1::text || 'b'",
+	}
+
+	var trace bytes.Buffer
+	conn.PgConn().Frontend().Trace(&trace, pgproto3.TracerOptions{SuppressTimestamps: true})
+	err := conn.PrepareAll(context.Background(), statements)
+	conn.PgConn().Frontend().Untrace()
+	require.NoError(t, err)
+
+	// All statements are prepared in a single round trip.
+	require.Equal(t, 2, strings.Count(trace.String(), "\tParse\t"))
+	require.Equal(t, 1, strings.Count(trace.String(), "\tSync\t"))
+
+	var n int32
+	err = conn.QueryRow(context.Background(), "ps_add", 41).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, n)
+
+	var s string
+	err = conn.QueryRow(context.Background(), "ps_concat", "a").Scan(&s)
+	require.NoError(t, err)
+	require.Equal(t, "ab", s)
+
+	err = conn.PrepareAll(context.Background(), map[string]string{"ps_bad": "select * from missing_table"})
+	require.ErrorContains(t, err, "failed to prepare statement ps_bad")
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "42P01", pgErr.Code)
+
+	ensureConnValid(t, conn)
+}
+
 func TestPrepareStatementCacheModes(t *testing.T) {
 	t.Parallel()
 
@@ -487,6 +641,129 @@ func TestPrepareStatementCacheModes(t *testing.T) {
 	})
 }
 
+func TestStatementNameFunc(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.StatementNameFunc = func(sql string) string { return "named_stmt" }
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	var n int32
+	err := conn.QueryRow(context.Background(), "select 1::int4").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	err = conn.QueryRow(context.Background(), "select 2::int4").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+
+	rows, _ := conn.Query(context.Background(), "select name from pg_prepared_statements order by name", pgx.QueryExecModeSimpleProtocol)
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	require.NoError(t, err)
+	require.Equal(t, []string{"named_stmt", "named_stmt_1"}, names)
+}
+
+func TestConnCancelRequest(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	if conn.PgConn().ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support query cancellation (https://github.com/cockroachdb/cockroach/issues/41335)")
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		conn.CancelRequest(context.Background())
+	}()
+
+	_, err := conn.Exec(context.Background(), "select pg_sleep(10)")
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "57014", pgErr.Code)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnTxStatus(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	require.EqualValues(t, 'I', conn.TxStatus())
+	require.False(t, conn.InTransaction())
+
+	tx, err := conn.Begin(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 'T', conn.TxStatus())
+	require.True(t, conn.InTransaction())
+
+	_, err = tx.Exec(context.Background(), "select 1/0")
+	require.Error(t, err)
+	require.EqualValues(t, 'E', conn.TxStatus())
+	require.True(t, conn.InTransaction())
+
+	err = tx.Rollback(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 'I', conn.TxStatus())
+	require.False(t, conn.InTransaction())
+}
+
+func TestConnWithPgConn(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	err := conn.WithPgConn(context.Background(), func(pgConn *pgconn.PgConn) error {
+		results, err := pgConn.Exec(context.Background(), "select 1; select 2").ReadAll()
+		if err != nil {
+			return err
+		}
+		require.Len(t, results, 2)
+		return nil
+	})
+	require.NoError(t, err)
+	ensureConnValid(t, conn)
+
+	err = conn.WithPgConn(context.Background(), func(pgConn *pgconn.PgConn) error {
+		pgConn.ExecParams(context.Background(), "select 1", nil, nil, nil, nil)
+		return nil
+	})
+	require.Error(t, err)
+	require.True(t, conn.IsClosed())
+}
+
+func TestConnSessionTimeZone(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.SessionTimeZone = "America/Chicago"
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	var tz string
+	err := conn.QueryRow(context.Background(), "show timezone").Scan(&tz)
+	require.NoError(t, err)
+	require.Equal(t, "America/Chicago", tz)
+
+	var tm time.Time
+	err = conn.QueryRow(context.Background(), "select '2022-07-01 12:00:00+00'::timestamptz").Scan(&tm)
+	require.NoError(t, err)
+	require.Equal(t, "America/Chicago", tm.Location().String())
+	require.True(t, tm.Equal(time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)))
+
+	config.SessionTimeZone = "Not/AZone"
+	_, err = pgx.ConnectConfig(context.Background(), config)
+	require.Error(t, err)
+}
+
 func TestListenNotify(t *testing.T) {
 	t.Parallel()
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/jackc/pgx/v5/internal/pgio"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -64,6 +65,133 @@ func (cts *copyFromSlice) Err() error {
 	return cts.err
 }
 
+// CopyFromHeader returns a CopyFromSource that reads the first row of src as a header of column names and yields the
+// remaining rows of src with their values reordered to match columnNames. Header values must be strings or []byte.
+// Columns in src that are not in columnNames are ignored. It is an error for a column in columnNames to be missing
+// from the header.
+func CopyFromHeader(src CopyFromSource, columnNames []string) CopyFromSource {
+	return &copyFromHeader{src: src, columnNames: columnNames}
+}
+
+type copyFromHeader struct {
+	src         CopyFromSource
+	columnNames []string
+	srcIdxs     []int
+	values      []any
+	err         error
+}
+
+func (cfh *copyFromHeader) Next() bool {
+	if cfh.err != nil {
+		return false
+	}
+
+	if cfh.srcIdxs == nil {
+		if !cfh.src.Next() {
+			return false
+		}
+		cfh.err = cfh.readHeader()
+		if cfh.err != nil {
+			return false
+		}
+	}
+
+	return cfh.src.Next()
+}
+
+func (cfh *copyFromHeader) readHeader() error {
+	header, err := cfh.src.Values()
+	if err != nil {
+		return err
+	}
+
+	headerIdxs := make(map[string]int, len(header))
+	for i, v := range header {
+		switch v := v.(type) {
+		case string:
+			headerIdxs[v] = i
+		case []byte:
+			headerIdxs[string(v)] = i
+		default:
+			return fmt.Errorf("header column %d is %T, not a string", i, v)
+		}
+	}
+
+	cfh.srcIdxs = make([]int, len(cfh.columnNames))
+	for i, name := range cfh.columnNames {
+		idx, ok := headerIdxs[name]
+		if !ok {
+			return fmt.Errorf("column %s missing from header", name)
+		}
+		cfh.srcIdxs[i] = idx
+	}
+	cfh.values = make([]any, len(cfh.columnNames))
+
+	return nil
+}
+
+func (cfh *copyFromHeader) Values() ([]any, error) {
+	srcValues, err := cfh.src.Values()
+	if err != nil {
+		cfh.err = err
+		return nil, err
+	}
+
+	for i, idx := range cfh.srcIdxs {
+		if idx >= len(srcValues) {
+			cfh.err = fmt.Errorf("row has %d values, but column %s is at position %d in header", len(srcValues), cfh.columnNames[i], idx)
+			return nil, cfh.err
+		}
+		cfh.values[i] = srcValues[idx]
+	}
+
+	return cfh.values, nil
+}
+
+func (cfh *copyFromHeader) Err() error {
+	if cfh.err != nil {
+		return cfh.err
+	}
+	return cfh.src.Err()
+}
+
+// CopyFromProgress returns a CopyFromSource that yields the rows of src and calls fn with the number of rows read so
+// far after every rows. fn is called from the goroutine that reads src, which for *Conn.CopyFrom is not the goroutine
+// that called CopyFrom. It is called synchronously while the copy waits, so it should return quickly. If every <= 0 fn
+// is never called.
+func CopyFromProgress(src CopyFromSource, every int64, fn func(rowCount int64)) CopyFromSource {
+	return &copyFromProgress{src: src, every: every, fn: fn}
+}
+
+type copyFromProgress struct {
+	src      CopyFromSource
+	every    int64
+	fn       func(rowCount int64)
+	rowCount int64
+}
+
+func (cfp *copyFromProgress) Next() bool {
+	return cfp.src.Next()
+}
+
+func (cfp *copyFromProgress) Values() ([]any, error) {
+	values, err := cfp.src.Values()
+	if err != nil {
+		return nil, err
+	}
+
+	cfp.rowCount++
+	if cfp.every > 0 && cfp.rowCount%cfp.every == 0 {
+		cfp.fn(cfp.rowCount)
+	}
+
+	return values, nil
+}
+
+func (cfp *copyFromProgress) Err() error {
+	return cfp.src.Err()
+}
+
 // CopyFromSource is the interface used by *Conn.CopyFrom as the source for copy data.
 type CopyFromSource interface {
 	// Next returns true if there is another row and makes the next row data
@@ -96,15 +224,7 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 		})
 	}
 
-	quotedTableName := ct.tableName.Sanitize()
-	cbuf := &bytes.Buffer{}
-	for i, cn := range ct.columnNames {
-		if i != 0 {
-			cbuf.WriteString(", ")
-		}
-		cbuf.WriteString(quoteIdentifier(cn))
-	}
-	quotedColumnNames := cbuf.String()
+	describeSQL, copySQL := copyFromSQL(ct.tableName, ct.columnNames)
 
 	var sd *pgconn.StatementDescription
 	switch ct.mode {
@@ -119,11 +239,7 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 		fallthrough
 	case QueryExecModeCacheStatement, QueryExecModeCacheDescribe, QueryExecModeDescribeExec:
 		var err error
-		sd, err = ct.conn.getStatementDescription(
-			ctx,
-			ct.mode,
-			fmt.Sprintf("select %s from %s", quotedColumnNames, quotedTableName),
-		)
+		sd, err = ct.conn.getStatementDescription(ctx, ct.mode, describeSQL)
 		if err != nil {
 			return 0, fmt.Errorf("statement description failed: %w", err)
 		}
@@ -172,7 +288,7 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 		w.Close()
 	}()
 
-	commandTag, err := ct.conn.pgConn.CopyFrom(ctx, r, fmt.Sprintf("copy %s ( %s ) from stdin binary;", quotedTableName, quotedColumnNames))
+	commandTag, err := ct.conn.pgConn.CopyFrom(ctx, r, copySQL)
 
 	r.Close()
 	<-doneChan
@@ -187,6 +303,43 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 	return commandTag.RowsAffected(), err
 }
 
+// copyFromSQL returns the query used to describe the types of columnNames and the COPY statement for tableName.
+func copyFromSQL(tableName Identifier, columnNames []string) (describeSQL, copySQL string) {
+	quotedTableName := tableName.Sanitize()
+	cbuf := &bytes.Buffer{}
+	for i, cn := range columnNames {
+		if i != 0 {
+			cbuf.WriteString(", ")
+		}
+		cbuf.WriteString(quoteIdentifier(cn))
+	}
+	quotedColumnNames := cbuf.String()
+
+	return fmt.Sprintf("select %s from %s", quotedColumnNames, quotedTableName),
+		fmt.Sprintf("copy %s ( %s ) from stdin binary;", quotedTableName, quotedColumnNames)
+}
+
+// buildCopyData encodes all rows of ct.rowSrc as binary copy data.
+func (ct *copyFrom) buildCopyData(sd *pgconn.StatementDescription) ([]byte, error) {
+	buf := []byte("PGCOPY\n\377\r\n\000")
+	buf = pgio.AppendInt32(buf, 0)
+	buf = pgio.AppendInt32(buf, 0)
+
+	for moreRows := true; moreRows; {
+		var err error
+		moreRows, buf, err = ct.buildCopyBuf(buf, sd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ct.rowSrc.Err() != nil {
+		return nil, ct.rowSrc.Err()
+	}
+
+	return buf, nil
+}
+
 func (ct *copyFrom) buildCopyBuf(buf []byte, sd *pgconn.StatementDescription) (bool, []byte, error) {
 	const sendBufSize = 65536 - 5 // The packet has a 5-byte header
 	lastBufLen := 0
@@ -247,3 +400,111 @@ func (c *Conn) CopyFrom(ctx context.Context, tableName Identifier, columnNames [
 
 	return ct.run(ctx)
 }
+
+// copyFromWithRejectsChunkSize is the number of rows CopyFromWithRejects copies at a time.
+const copyFromWithRejectsChunkSize = 1000
+
+// CopyFromWithRejects copies rows like CopyFrom but does not abort on rows the server rejects. Rows are read from
+// rowSrc and copied in chunks, each in its own transaction (or savepoint if db is a Tx). If copying a chunk fails, the
+// rows of that chunk are inserted one at a time instead. onReject is called with each row that
+// cannot be inserted and the error. If onReject returns an error the copy stops and that error is returned. Accepted
+// rows are committed and the number of accepted rows is returned.
+//
+// Errors that leave the connection unusable (e.g. a network failure) or are caused by ctx stop the copy and are
+// returned.
+func CopyFromWithRejects(
+	ctx context.Context,
+	db interface {
+		Begin(ctx context.Context) (Tx, error)
+	},
+	tableName Identifier,
+	columnNames []string,
+	rowSrc CopyFromSource,
+	onReject func(row []any, err error) error,
+) (int64, error) {
+	quotedColumnNames := make([]string, len(columnNames))
+	placeholders := make([]string, len(columnNames))
+	for i, cn := range columnNames {
+		quotedColumnNames[i] = quoteIdentifier(cn)
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	insertSQL := fmt.Sprintf("insert into %s (%s) values (%s)", tableName.Sanitize(), strings.Join(quotedColumnNames, ", "), strings.Join(placeholders, ", "))
+
+	var accepted int64
+	chunk := make([][]any, 0, copyFromWithRejectsChunkSize)
+
+	// unrecoverable reports if the last error means the copy cannot continue rather than that rows were rejected.
+	var conn *Conn
+	unrecoverable := func() bool {
+		return ctx.Err() != nil || conn == nil || conn.IsClosed()
+	}
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		defer func() { chunk = chunk[:0] }()
+
+		var n int64
+		conn = nil
+		err := BeginFunc(ctx, db, func(tx Tx) error {
+			conn = tx.Conn()
+			var err error
+			n, err = tx.CopyFrom(ctx, tableName, columnNames, CopyFromRows(chunk))
+			return err
+		})
+		if err == nil {
+			accepted += n
+			return nil
+		}
+		if unrecoverable() {
+			return err
+		}
+
+		for _, row := range chunk {
+			conn = nil
+			err := BeginFunc(ctx, db, func(tx Tx) error {
+				conn = tx.Conn()
+				_, err := tx.Exec(ctx, insertSQL, row...)
+				return err
+			})
+			if err == nil {
+				accepted++
+				continue
+			}
+			if unrecoverable() {
+				return err
+			}
+
+			err = onReject(row, err)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for rowSrc.Next() {
+		values, err := rowSrc.Values()
+		if err != nil {
+			return accepted, err
+		}
+
+		// The source may reuse its slice between rows.
+		chunk = append(chunk, append([]any(nil), values...))
+		if len(chunk) == copyFromWithRejectsChunkSize {
+			err = flush()
+			if err != nil {
+				return accepted, err
+			}
+		}
+	}
+
+	if err := rowSrc.Err(); err != nil {
+		return accepted, err
+	}
+
+	err := flush()
+	return accepted, err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -767,3 +768,114 @@ func TestConnCopyFromAutomaticStringConversion(t *testing.T) {
 
 	ensureConnValid(t, conn)
 }
+
+func TestCopyFromHeader(t *testing.T) {
+	t.Parallel()
+
+	src := pgx.CopyFromHeader(pgx.CopyFromRows([][]any{
+		{"b", "extra", "a"},
+		{"b1", "x", int32(1)},
+		{"b2", "y", int32(2)},
+	}), []string{"a", "b"})
+
+	var rows [][]any
+	for src.Next() {
+		values, err := src.Values()
+		require.NoError(t, err)
+		rows = append(rows, append([]any(nil), values...))
+	}
+	require.NoError(t, src.Err())
+	require.Equal(t, [][]any{{int32(1), "b1"}, {int32(2), "b2"}}, rows)
+
+	src = pgx.CopyFromHeader(pgx.CopyFromRows([][]any{
+		{"b"},
+		{"b1"},
+	}), []string{"a", "b"})
+	require.False(t, src.Next())
+	require.EqualError(t, src.Err(), "column a missing from header")
+}
+
+func TestCopyFromProgress(t *testing.T) {
+	t.Parallel()
+
+	var progress []int64
+	src := pgx.CopyFromProgress(pgx.CopyFromSlice(100000, func(i int) ([]any, error) {
+		return []any{int32(i)}, nil
+	}), 10000, func(rowCount int64) {
+		progress = append(progress, rowCount)
+	})
+
+	var n int
+	for src.Next() {
+		_, err := src.Values()
+		require.NoError(t, err)
+		n++
+	}
+	require.NoError(t, src.Err())
+	require.Equal(t, 100000, n)
+	require.Equal(t, []int64{10000, 20000, 30000, 40000, 50000, 60000, 70000, 80000, 90000, 100000}, progress)
+}
+
+func TestConnCopyFromProgress(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table foo(a int4)`)
+
+		var calls int64
+		var lastRowCount int64
+		src := pgx.CopyFromProgress(pgx.CopyFromSlice(25000, func(i int) ([]any, error) {
+			return []any{int32(i)}, nil
+		}), 10000, func(rowCount int64) {
+			atomic.AddInt64(&calls, 1)
+			atomic.StoreInt64(&lastRowCount, rowCount)
+		})
+
+		copyCount, err := conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a"}, src)
+		require.NoError(t, err)
+		require.EqualValues(t, 25000, copyCount)
+		require.EqualValues(t, 2, atomic.LoadInt64(&calls))
+		require.EqualValues(t, 20000, atomic.LoadInt64(&lastRowCount))
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestCopyFromWithRejects(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table foo(a int4 primary key, b text not null)`)
+
+		inputRows := make([][]any, 0, 2500)
+		for i := 1; i <= 2500; i++ {
+			var b any = fmt.Sprint(i)
+			if i == 1500 {
+				b = nil
+			}
+			inputRows = append(inputRows, []any{int32(i), b})
+		}
+		// Duplicate key
+		inputRows = append(inputRows, []any{int32(10), "dup"})
+
+		var rejects [][]any
+		copyCount, err := pgx.CopyFromWithRejects(ctx, conn, pgx.Identifier{"foo"}, []string{"a", "b"}, pgx.CopyFromRows(inputRows),
+			func(row []any, err error) error {
+				var pgErr *pgconn.PgError
+				require.ErrorAs(t, err, &pgErr)
+				rejects = append(rejects, row)
+				return nil
+			},
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, 2499, copyCount)
+		require.Equal(t, [][]any{{int32(1500), nil}, {int32(10), "dup"}}, rejects)
+
+		var n int64
+		err = conn.QueryRow(ctx, "select count(*) from foo").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 2499, n)
+
+		ensureConnValid(t, conn)
+	})
+}
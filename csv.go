@@ -0,0 +1,81 @@
+package pgx
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// writeCSVFlushInterval is the number of rows WriteCSV writes between flushes to the underlying io.Writer.
+const writeCSVFlushInterval = 1000
+
+// WriteCSV executes sql with args on db and writes the result to w as CSV. The first record is a header of the field
+// names. Each value is written in its PostgreSQL text format and NULL is written as an empty field. Rows are streamed
+// to w and flushed periodically so the result set is never buffered in memory. It returns the number of data rows
+// written.
+func WriteCSV(
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	w io.Writer,
+	sql string,
+	args ...any,
+) (int64, error) {
+	queryArgs := make([]any, 0, len(args)+1)
+	queryArgs = append(queryArgs, QueryResultFormats{pgtype.TextFormatCode})
+	queryArgs = append(queryArgs, args...)
+
+	rows, err := db.Query(ctx, sql, queryArgs...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	cw := csv.NewWriter(w)
+
+	fieldDescriptions := rows.FieldDescriptions()
+	record := make([]string, len(fieldDescriptions))
+	for i, fd := range fieldDescriptions {
+		record[i] = fd.Name
+	}
+	err = cw.Write(record)
+	if err != nil {
+		return 0, err
+	}
+
+	var rowCount int64
+	for rows.Next() {
+		for i, value := range rows.RawValues() {
+			record[i] = string(value)
+		}
+		err = cw.Write(record)
+		if err != nil {
+			return rowCount, err
+		}
+		rowCount++
+
+		if rowCount%writeCSVFlushInterval == 0 {
+			cw.Flush()
+			err = cw.Error()
+			if err != nil {
+				return rowCount, err
+			}
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return rowCount, err
+	}
+
+	cw.Flush()
+	err = cw.Error()
+	if err != nil {
+		return rowCount, err
+	}
+
+	return rowCount, nil
+}
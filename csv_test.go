@@ -0,0 +1,31 @@
+package pgx_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var buf bytes.Buffer
+		n, err := pgx.WriteCSV(ctx, conn, &buf,
+			`select n, case when n = 2 then null else 'a,"b"' || n end as s from generate_series(1, $1::int4) n`,
+			3,
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+		require.Equal(t, "n,s\n1,\"a,\"\"b\"\"1\"\n2,\n3,\"a,\"\"b\"\"3\"\n", buf.String())
+
+		buf.Reset()
+		_, err = pgx.WriteCSV(ctx, conn, &buf, "select * from missing_write_csv_table")
+		require.Error(t, err)
+		require.Equal(t, 0, buf.Len())
+	})
+}
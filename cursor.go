@@ -0,0 +1,164 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// QueueDeclareCursor queues a DECLARE of a cursor named name for query to batch b. The rows of the cursor are not
+// returned by the batch. Instead, after the batch results are closed, they can be read a chunk at a time with
+// CursorRows. This bounds the memory used by a query with a huge result while still running it in the same
+// transaction as the rest of the batch.
+//
+// A cursor only exists until the end of the transaction in which it was declared. A batch sent outside of a
+// transaction runs in an implicit transaction that ends when the batch completes so the batch must be sent on a Tx
+// (or begin a transaction itself) for the cursor to still exist when it is read.
+func (b *Batch) QueueDeclareCursor(name, query string, arguments ...any) *QueuedQuery {
+	return b.Queue("declare "+Identifier{name}.Sanitize()+" cursor for "+query, arguments...)
+}
+
+// CursorRows returns Rows that reads the rows of the cursor name by fetching fetchSize rows at a time. db must be the
+// transaction or connection in which the cursor was declared. Closing the returned Rows closes the cursor.
+func CursorRows(
+	ctx context.Context,
+	db interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	name string,
+	fetchSize int,
+) Rows {
+	r := &cursorRows{ctx: ctx, db: db, name: Identifier{name}.Sanitize()}
+	if fetchSize <= 0 {
+		r.err = errors.New("fetch size must be greater than 0")
+		r.closed = true
+		return r
+	}
+	r.fetchSQL = "fetch forward " + strconv.Itoa(fetchSize) + " from " + r.name
+	r.fetchSize = fetchSize
+	return r
+}
+
+type cursorRows struct {
+	ctx context.Context
+	db  interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	}
+	name      string
+	fetchSQL  string
+	fetchSize int
+
+	rows              Rows // rows of the current fetch
+	conn              *Conn
+	fieldDescriptions []pgconn.FieldDescription
+	chunkRowCount     int
+	rowCount          int64
+	exhausted         bool
+	closed            bool
+	err               error
+}
+
+func (r *cursorRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+
+	if r.rows != nil {
+		r.rows.Close()
+		if r.err == nil {
+			r.err = r.rows.Err()
+		}
+		r.rows = nil
+	}
+
+	// The transaction is aborted after an error so the cursor cannot be closed and will be released with it.
+	if r.err == nil {
+		_, r.err = r.db.Exec(r.ctx, "close "+r.name)
+	}
+}
+
+func (r *cursorRows) Err() error {
+	return r.err
+}
+
+func (r *cursorRows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag(fmt.Sprintf("FETCH %d", r.rowCount))
+}
+
+func (r *cursorRows) FieldDescriptions() []pgconn.FieldDescription {
+	return r.fieldDescriptions
+}
+
+func (r *cursorRows) Next() bool {
+	for !r.closed {
+		if r.rows == nil {
+			if r.exhausted {
+				r.Close()
+				return false
+			}
+
+			r.rows, r.err = r.db.Query(r.ctx, r.fetchSQL)
+			if r.err != nil {
+				r.Close()
+				return false
+			}
+			r.chunkRowCount = 0
+			r.conn = r.rows.Conn()
+			if r.fieldDescriptions == nil {
+				r.fieldDescriptions = r.rows.FieldDescriptions()
+			}
+		}
+
+		if r.rows.Next() {
+			r.chunkRowCount++
+			r.rowCount++
+			return true
+		}
+
+		r.rows.Close()
+		r.err = r.rows.Err()
+		r.rows = nil
+		if r.err != nil {
+			r.Close()
+			return false
+		}
+
+		// A short fetch means the end of the cursor has been reached.
+		if r.chunkRowCount < r.fetchSize {
+			r.exhausted = true
+		}
+	}
+
+	return false
+}
+
+func (r *cursorRows) Scan(dest ...any) error {
+	if r.rows == nil {
+		return errors.New("no current row")
+	}
+	return r.rows.Scan(dest...)
+}
+
+func (r *cursorRows) Values() ([]any, error) {
+	if r.rows == nil {
+		return nil, errors.New("no current row")
+	}
+	return r.rows.Values()
+}
+
+func (r *cursorRows) RawValues() [][]byte {
+	if r.rows == nil {
+		return nil
+	}
+	return r.rows.RawValues()
+}
+
+func (r *cursorRows) Conn() *Conn {
+	return r.conn
+}
@@ -0,0 +1,53 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchDeclareCursor(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.QueueDeclareCursor("batch_cursor", "select n from generate_series(1, $1::int4) n", 10)
+		err = tx.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		rows := pgx.CursorRows(ctx, tx, "batch_cursor", 3)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, numbers)
+		require.EqualValues(t, 10, rows.CommandTag().RowsAffected())
+
+		// The cursor is closed with the rows.
+		_, err = tx.Exec(ctx, "fetch 1 from batch_cursor")
+		require.Error(t, err)
+	})
+}
+
+func TestCursorRowsExactMultipleOfFetchSize(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, "declare c cursor for select n from generate_series(1, 6) n")
+		require.NoError(t, err)
+
+		numbers, err := pgx.CollectRows(pgx.CursorRows(ctx, tx, "c", 3), pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3, 4, 5, 6}, numbers)
+	})
+}
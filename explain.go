@@ -0,0 +1,137 @@
+package pgx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ExplainPlan is the query plan returned by ExplainJSON.
+type ExplainPlan struct {
+	// Root is the top node of the plan tree.
+	Root *ExplainPlanNode
+
+	// PlanningTime is the time in milliseconds spent planning the query. It is only reported by EXPLAIN ANALYZE.
+	PlanningTime float64
+
+	// ExecutionTime is the time in milliseconds spent executing the query. It is only reported by EXPLAIN ANALYZE.
+	ExecutionTime float64
+}
+
+// ExplainPlanNode is a single node of a query plan. Costs are in the planner's arbitrary units. Actual times are in
+// milliseconds and actual rows are averages per loop. The actual fields are only reported by EXPLAIN ANALYZE.
+type ExplainPlanNode struct {
+	NodeType     string
+	RelationName string
+	Alias        string
+
+	StartupCost float64
+	TotalCost   float64
+	PlanRows    float64
+	PlanWidth   int
+
+	ActualStartupTime float64
+	ActualTotalTime   float64
+	ActualRows        float64
+	ActualLoops       float64
+
+	// Properties contains the properties of the node that do not have a dedicated field (e.g. "Index Name" or
+	// "Filter"), keyed by the name PostgreSQL uses.
+	Properties map[string]any
+
+	// Children are the nodes whose output this node consumes.
+	Children []*ExplainPlanNode
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler for a plan node as formatted by EXPLAIN (FORMAT JSON).
+func (n *ExplainPlanNode) UnmarshalJSON(src []byte) error {
+	var fields struct {
+		NodeType          string             `json:"Node Type"`
+		RelationName      string             `json:"Relation Name"`
+		Alias             string             `json:"Alias"`
+		StartupCost       float64            `json:"Startup Cost"`
+		TotalCost         float64            `json:"Total Cost"`
+		PlanRows          float64            `json:"Plan Rows"`
+		PlanWidth         int                `json:"Plan Width"`
+		ActualStartupTime float64            `json:"Actual Startup Time"`
+		ActualTotalTime   float64            `json:"Actual Total Time"`
+		ActualRows        float64            `json:"Actual Rows"`
+		ActualLoops       float64            `json:"Actual Loops"`
+		Plans             []*ExplainPlanNode `json:"Plans"`
+	}
+	err := json.Unmarshal(src, &fields)
+	if err != nil {
+		return err
+	}
+
+	var properties map[string]any
+	err = json.Unmarshal(src, &properties)
+	if err != nil {
+		return err
+	}
+	for _, k := range []string{
+		"Node Type", "Relation Name", "Alias",
+		"Startup Cost", "Total Cost", "Plan Rows", "Plan Width",
+		"Actual Startup Time", "Actual Total Time", "Actual Rows", "Actual Loops",
+		"Plans",
+	} {
+		delete(properties, k)
+	}
+
+	*n = ExplainPlanNode{
+		NodeType:          fields.NodeType,
+		RelationName:      fields.RelationName,
+		Alias:             fields.Alias,
+		StartupCost:       fields.StartupCost,
+		TotalCost:         fields.TotalCost,
+		PlanRows:          fields.PlanRows,
+		PlanWidth:         fields.PlanWidth,
+		ActualStartupTime: fields.ActualStartupTime,
+		ActualTotalTime:   fields.ActualTotalTime,
+		ActualRows:        fields.ActualRows,
+		ActualLoops:       fields.ActualLoops,
+		Properties:        properties,
+		Children:          fields.Plans,
+	}
+
+	return nil
+}
+
+// ExplainJSON runs EXPLAIN (FORMAT JSON) for sql with args and returns the parsed plan. If analyze is true EXPLAIN
+// ANALYZE is used, which executes sql to report actual times and row counts. Use a transaction that is rolled back to
+// analyze a statement without keeping its side effects.
+func (c *Conn) ExplainJSON(ctx context.Context, analyze bool, sql string, args ...any) (*ExplainPlan, error) {
+	explainSQL := "explain (format json) " + sql
+	if analyze {
+		explainSQL = "explain (format json, analyze) " + sql
+	}
+
+	var buf []byte
+	err := c.QueryRow(ctx, explainSQL, args...).Scan(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExplainJSON(buf)
+}
+
+func parseExplainJSON(src []byte) (*ExplainPlan, error) {
+	var results []struct {
+		Plan          *ExplainPlanNode `json:"Plan"`
+		PlanningTime  float64          `json:"Planning Time"`
+		ExecutionTime float64          `json:"Execution Time"`
+	}
+	err := json.Unmarshal(src, &results)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse EXPLAIN output: %w", err)
+	}
+	if len(results) != 1 || results[0].Plan == nil {
+		return nil, fmt.Errorf("cannot parse EXPLAIN output: expected 1 plan, got %d", len(results))
+	}
+
+	return &ExplainPlan{
+		Root:          results[0].Plan,
+		PlanningTime:  results[0].PlanningTime,
+		ExecutionTime: results[0].ExecutionTime,
+	}, nil
+}
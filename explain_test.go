@@ -0,0 +1,63 @@
+package pgx_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnExplainJSON(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		plan, err := conn.ExplainJSON(ctx, false, "select n from generate_series(1, $1::int) n where n > 5", 10)
+		require.NoError(t, err)
+		require.NotNil(t, plan.Root)
+		require.Equal(t, "Function Scan", plan.Root.NodeType)
+		require.Greater(t, plan.Root.TotalCost, 0.0)
+		require.Contains(t, plan.Root.Properties, "Filter")
+		require.Zero(t, plan.ExecutionTime)
+
+		plan, err = conn.ExplainJSON(ctx, true, "select * from generate_series(1, 10) a cross join generate_series(1, 3) b")
+		require.NoError(t, err)
+		require.Equal(t, "Nested Loop", plan.Root.NodeType)
+		require.Len(t, plan.Root.Children, 2)
+		require.EqualValues(t, 30, plan.Root.ActualRows)
+		require.EqualValues(t, 1, plan.Root.ActualLoops)
+		require.Greater(t, plan.ExecutionTime, 0.0)
+	})
+}
+
+func TestExplainPlanNodeUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	src := `{
+  "Node Type": "Index Scan",
+  "Relation Name": "widgets",
+  "Alias": "w",
+  "Index Name": "widgets_pkey",
+  "Startup Cost": 0.15,
+  "Total Cost": 8.17,
+  "Plan Rows": 1,
+  "Plan Width": 36,
+  "Plans": [{"Node Type": "Result", "Total Cost": 0.01}]
+}`
+
+	var node pgx.ExplainPlanNode
+	err := json.Unmarshal([]byte(src), &node)
+	require.NoError(t, err)
+	require.Equal(t, "Index Scan", node.NodeType)
+	require.Equal(t, "widgets", node.RelationName)
+	require.Equal(t, "w", node.Alias)
+	require.Equal(t, 0.15, node.StartupCost)
+	require.Equal(t, 8.17, node.TotalCost)
+	require.Equal(t, 1.0, node.PlanRows)
+	require.Equal(t, 36, node.PlanWidth)
+	require.Equal(t, map[string]any{"Index Name": "widgets_pkey"}, node.Properties)
+	require.Len(t, node.Children, 1)
+	require.Equal(t, "Result", node.Children[0].NodeType)
+	require.Equal(t, 0.01, node.Children[0].TotalCost)
+}
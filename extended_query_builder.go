@@ -143,7 +143,7 @@ func (eqb *ExtendedQueryBuilder) chooseParameterFormatCode(m *pgtype.Map, oid ui
 		return TextFormatCode
 	}
 
-	return m.FormatCodeForOID(oid)
+	return m.ParamFormatCodeForOID(oid)
 }
 
 // appendParamsForQueryExecModeExec appends the args to eqb.
@@ -320,3 +320,130 @@ func SanitizeSQL(sql string, args ...any) (string, error) {
 	}
 	return query.Sanitize(args...)
 }
+
+// MultipleStatements reports whether sql contains more than one statement. Semicolons inside string literals, quoted
+// identifiers, dollar-quoted strings, and comments are ignored. A trailing semicolon does not start another statement.
+func MultipleStatements(sql string) bool {
+	ended := false
+
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; c {
+		case ' ', '\t', '\n', '\r', '\f':
+		case ';':
+			ended = true
+		case '-':
+			if strings.HasPrefix(sql[i:], "--") {
+				n := strings.IndexAny(sql[i:], "\n\r")
+				if n < 0 {
+					return false
+				}
+				i += n
+				continue
+			}
+			if ended {
+				return true
+			}
+		case '/':
+			if strings.HasPrefix(sql[i:], "/*") {
+				n := multilineCommentLen(sql[i:])
+				if n < 0 {
+					return false
+				}
+				i += n - 1
+				continue
+			}
+			if ended {
+				return true
+			}
+		default:
+			if ended {
+				return true
+			}
+
+			var n int
+			switch {
+			case c == '\'' && i > 0 && (sql[i-1] == 'e' || sql[i-1] == 'E') && (i == 1 || !isIdentByte(sql[i-2])):
+				n = quotedLen(sql[i:], '\'', true)
+			case c == '\'' || c == '"':
+				n = quotedLen(sql[i:], c, false)
+			case c == '$' && (i == 0 || !isIdentByte(sql[i-1])):
+				n = dollarQuotedLen(sql[i:])
+			default:
+				continue
+			}
+			if n < 0 {
+				return false
+			}
+			if n > 0 {
+				i += n - 1
+			}
+		}
+	}
+
+	return false
+}
+
+// quotedLen returns the length of the quoted string or identifier at the start of src including the quotes or -1 if
+// it is not terminated. A doubled quote is treated as the end of one quoted string and the start of another, which
+// does not change the result.
+func quotedLen(src string, quote byte, backslashEscapes bool) int {
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// dollarQuotedLen returns the length of the dollar-quoted string at the start of src, 0 if src does not start with a
+// dollar quote tag, or -1 if the string is not terminated.
+func dollarQuotedLen(src string) int {
+	end := 1
+	for end < len(src) && src[end] != '$' {
+		c := src[end]
+		if !isIdentByte(c) || c == '$' || (end == 1 && '0' <= c && c <= '9') {
+			return 0
+		}
+		end++
+	}
+	if end == len(src) {
+		return 0
+	}
+
+	tag := src[:end+1]
+	n := strings.Index(src[len(tag):], tag)
+	if n < 0 {
+		return -1
+	}
+	return len(tag) + n + len(tag)
+}
+
+// multilineCommentLen returns the length of the possibly nested comment at the start of src or -1 if it is not
+// terminated.
+func multilineCommentLen(src string) int {
+	nested := 0
+	for i := 2; i < len(src)-1; i++ {
+		switch {
+		case src[i] == '/' && src[i+1] == '*':
+			nested++
+			i++
+		case src[i] == '*' && src[i+1] == '/':
+			if nested == 0 {
+				return i + 2
+			}
+			nested--
+			i++
+		}
+	}
+	return -1
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= utf8.RuneSelf ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -217,3 +217,42 @@ func TestQuerySanitize(t *testing.T) {
 		}
 	}
 }
+
+func TestMultipleStatements(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected bool
+	}{
+		{sql: "select 1", expected: false},
+		{sql: "select 1;", expected: false},
+		{sql: "select 1 ; \n", expected: false},
+		{sql: "select 1; -- comment", expected: false},
+		{sql: "select 1; /* comment */", expected: false},
+		{sql: "select 1;;", expected: false},
+		{sql: "select 'a;b'", expected: false},
+		{sql: "select 'it''s;'", expected: false},
+		{sql: `select e'it\'s;'`, expected: false},
+		{sql: `select "a;b"`, expected: false},
+		{sql: "select 1 -- a;b\n", expected: false},
+		{sql: "select 1 /* a; /* nested; */ b; */", expected: false},
+		{sql: "select $$a;b$$", expected: false},
+		{sql: "select $tag$a;$$;b$tag$", expected: false},
+		{sql: "create function f() returns int language sql as $body$ select 1; select 2 $body$", expected: false},
+		{sql: "select $1", expected: false},
+		{sql: "select 1; select 2", expected: true},
+		{sql: "select 1;select 2;", expected: true},
+		{sql: "select 'a;b'; select 2", expected: true},
+		{sql: "select $$a;b$$; select 2", expected: true},
+		{sql: "select 1; -- comment\nselect 2", expected: true},
+		{sql: "select 1; /* comment */ select 2", expected: true},
+		{sql: "select 1; 'a'", expected: true},
+		{sql: "select 'unterminated; select 2", expected: false},
+	}
+
+	for i, tt := range tests {
+		actual := sanitize.MultipleStatements(tt.sql)
+		if actual != tt.expected {
+			t.Errorf("%d. %q: expected %v, got %v", i, tt.sql, tt.expected, actual)
+		}
+	}
+}
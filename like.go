@@ -0,0 +1,28 @@
+package pgx
+
+import "strings"
+
+// EscapeLikePattern escapes the LIKE and ILIKE wildcards % and _ and escapeChar itself in s with escapeChar so that s
+// matches only itself. escapeChar must be an ASCII character and must be given as the ESCAPE clause of the LIKE
+// expression. The result can then be combined with wildcards and passed as a query argument. For example:
+//
+//	pattern := "%" + pgx.EscapeLikePattern(userInput, '\\') + "%"
+//	rows, err := conn.Query(ctx, `select name from widgets where name like $1 escape '\'`, pattern)
+//
+// The ESCAPE clause should always be given explicitly as the default escape character depends on server settings.
+func EscapeLikePattern(s string, escapeChar byte) string {
+	if escapeChar >= 0x80 {
+		panic("escapeChar must be an ASCII character")
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' || c == '_' || c == escapeChar {
+			sb.WriteByte(escapeChar)
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
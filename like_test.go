@@ -0,0 +1,61 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeLikePattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s          string
+		escapeChar byte
+		expected   string
+	}{
+		{s: "", escapeChar: '\\', expected: ""},
+		{s: "abc", escapeChar: '\\', expected: "abc"},
+		{s: "100%", escapeChar: '\\', expected: `100\%`},
+		{s: "a_b", escapeChar: '\\', expected: `a\_b`},
+		{s: `a\b`, escapeChar: '\\', expected: `a\\b`},
+		{s: `\%_`, escapeChar: '\\', expected: `\\\%\_`},
+		{s: "a!b%", escapeChar: '!', expected: "a!!b!%"},
+		{s: `a\b`, escapeChar: '!', expected: `a\b`},
+		{s: "ünï_cødé", escapeChar: '\\', expected: `ünï\_cødé`},
+	}
+
+	for i, tt := range tests {
+		assert.Equalf(t, tt.expected, pgx.EscapeLikePattern(tt.s, tt.escapeChar), "%d", i)
+	}
+
+	assert.Panics(t, func() { pgx.EscapeLikePattern("a", 0xff) })
+}
+
+func TestEscapeLikePatternQuery(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		for _, s := range []string{"100%", "a_b", `a\b`, `\%_`, "plain"} {
+			for _, escapeChar := range []byte{'\\', '!'} {
+				var matches bool
+				pattern := pgx.EscapeLikePattern(s, escapeChar)
+				err := conn.QueryRow(ctx, "select $1::text like $2::text escape $3::text", s, pattern, string(escapeChar)).Scan(&matches)
+				require.NoError(t, err)
+				require.Truef(t, matches, "%q escaped with %q", s, escapeChar)
+			}
+		}
+
+		// Escaped wildcards must not match other characters.
+		for s, other := range map[string]string{"100%": "1000", "a_b": "axb", `\%_`: `\xx`} {
+			var matches bool
+			err := conn.QueryRow(ctx, `select $1::text like $2::text escape '\'`, other, pgx.EscapeLikePattern(s, '\\')).Scan(&matches)
+			require.NoError(t, err)
+			require.Falsef(t, matches, "%q matched %q", other, s)
+		}
+	})
+}
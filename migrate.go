@@ -0,0 +1,117 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MigrationError is returned by Migrate when a statement of a migration fails.
+type MigrationError struct {
+	Version int64  // Version of the migration.
+	Index   int    // Index of the failed statement.
+	SQL     string // SQL of the failed statement.
+	Err     error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("migration %d statement %d failed: %v", e.Version, e.Index, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// nonTransactionalStatementPrefixes are the beginnings of statements that PostgreSQL refuses to run inside a
+// transaction block.
+var nonTransactionalStatementPrefixes = []string{
+	"create database",
+	"drop database",
+	"create tablespace",
+	"drop tablespace",
+	"create index concurrently",
+	"create unique index concurrently",
+	"drop index concurrently",
+	"reindex database",
+	"reindex system",
+	"vacuum",
+	"alter system",
+}
+
+// Migrate applies a migration of version to db. statements are executed in order in a single transaction and version
+// is recorded in versionTable only if all statements succeed. versionTable is created if it does not exist. If
+// version is already recorded in versionTable the migration is skipped.
+//
+// Statements are executed one at a time rather than sent as a Batch because a later statement may depend on the
+// schema created by an earlier one, and a Batch may prepare every statement before executing any of them. Statements
+// that cannot run inside a transaction block (e.g. CREATE INDEX CONCURRENTLY) are rejected before the transaction is
+// started. If a statement fails the error is a *MigrationError.
+func Migrate(
+	ctx context.Context,
+	db interface {
+		Begin(ctx context.Context) (Tx, error)
+	},
+	versionTable Identifier,
+	version int64,
+	statements []string,
+) error {
+	if len(versionTable) == 0 {
+		return errors.New("version table name must not be empty")
+	}
+
+	for i, sql := range statements {
+		if !canRunInTransaction(sql) {
+			return &MigrationError{
+				Version: version,
+				Index:   i,
+				SQL:     sql,
+				Err:     errors.New("statement cannot run inside a transaction block"),
+			}
+		}
+	}
+
+	table := versionTable.Sanitize()
+
+	return BeginFunc(ctx, db, func(tx Tx) error {
+		_, err := tx.Exec(ctx, "create table if not exists "+table+" (version bigint primary key, applied_at timestamptz not null default now())")
+		if err != nil {
+			return err
+		}
+
+		// Serialize concurrent migrations.
+		_, err = tx.Exec(ctx, "lock table "+table+" in exclusive mode")
+		if err != nil {
+			return err
+		}
+
+		var applied bool
+		err = tx.QueryRow(ctx, "select exists(select 1 from "+table+" where version = $1)", version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			return nil
+		}
+
+		for i, sql := range statements {
+			_, err = tx.Exec(ctx, sql)
+			if err != nil {
+				return &MigrationError{Version: version, Index: i, SQL: sql, Err: err}
+			}
+		}
+
+		_, err = tx.Exec(ctx, "insert into "+table+" (version) values ($1)", version)
+		return err
+	})
+}
+
+func canRunInTransaction(sql string) bool {
+	normalized := strings.ToLower(strings.Join(strings.Fields(sql), " "))
+	for _, prefix := range nonTransactionalStatementPrefixes {
+		if strings.HasPrefix(normalized, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	ctx := context.Background()
+	versionTable := pgx.Identifier{"pgx_test_migrate_versions"}
+
+	mustExec(t, conn, "drop table if exists pgx_test_migrate_versions, pgx_test_migrate_widgets")
+	defer mustExec(t, conn, "drop table if exists pgx_test_migrate_versions, pgx_test_migrate_widgets")
+
+	err := pgx.Migrate(ctx, conn, versionTable, 1, []string{
+		"create table pgx_test_migrate_widgets (id int primary key)",
+		"alter table pgx_test_migrate_widgets add column name text",
+		"insert into pgx_test_migrate_widgets (id, name) values (1, 'foo')",
+	})
+	require.NoError(t, err)
+
+	// Already applied migrations are skipped.
+	err = pgx.Migrate(ctx, conn, versionTable, 1, []string{"insert into pgx_test_migrate_widgets (id, name) values (2, 'bar')"})
+	require.NoError(t, err)
+
+	err = pgx.Migrate(ctx, conn, versionTable, 2, []string{
+		"alter table pgx_test_migrate_widgets add column size int",
+		"alter table pgx_test_migrate_widgets add column missing_type no_such_type",
+	})
+	var migrationErr *pgx.MigrationError
+	require.ErrorAs(t, err, &migrationErr)
+	require.EqualValues(t, 2, migrationErr.Version)
+	require.Equal(t, 1, migrationErr.Index)
+
+	err = pgx.Migrate(ctx, conn, versionTable, 3, []string{"create index concurrently on pgx_test_migrate_widgets (name)"})
+	require.ErrorAs(t, err, &migrationErr)
+	require.Equal(t, 0, migrationErr.Index)
+
+	rows, _ := conn.Query(ctx, "select version from pgx_test_migrate_versions order by version")
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, versions)
+
+	rows, _ = conn.Query(ctx, "select column_name from information_schema.columns where table_name = 'pgx_test_migrate_widgets' order by ordinal_position")
+	columns, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name"}, columns)
+
+	var count int
+	err = conn.QueryRow(ctx, "select count(*) from pgx_test_migrate_widgets").Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,87 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrConcurrentModification occurs when UpdateWithVersion finds the row but its version does not match the expected
+// version. This means the row was modified since it was read.
+var ErrConcurrentModification = errors.New("row was modified concurrently")
+
+// UpdateWithVersion sets the columns of values on the row of table where keyColumn equals key using optimistic
+// concurrency control. The update only succeeds if versionColumn still equals version. versionColumn is incremented by
+// the update and the new version is returned.
+//
+// If the row exists but has a different version the error is ErrConcurrentModification. If the row does not exist the
+// error is ErrNoRows.
+func UpdateWithVersion(
+	ctx context.Context,
+	db interface {
+		QueryRow(ctx context.Context, sql string, args ...any) Row
+	},
+	table Identifier,
+	keyColumn string,
+	key any,
+	versionColumn string,
+	version int64,
+	values map[string]any,
+) (int64, error) {
+	if len(table) == 0 {
+		return 0, errors.New("table name must not be empty")
+	}
+	if keyColumn == "" || versionColumn == "" {
+		return 0, errors.New("key and version columns must not be empty")
+	}
+	if len(values) == 0 {
+		return 0, errors.New("values must not be empty")
+	}
+
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		if column == versionColumn {
+			return 0, fmt.Errorf("values must not include the version column %q", versionColumn)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	quotedTable := table.Sanitize()
+	quotedKey := Identifier{keyColumn}.Sanitize()
+	quotedVersion := Identifier{versionColumn}.Sanitize()
+
+	args := make([]any, 0, len(columns)+2)
+	var sb strings.Builder
+	sb.WriteString("update ")
+	sb.WriteString(quotedTable)
+	sb.WriteString(" set ")
+	for _, column := range columns {
+		args = append(args, values[column])
+		fmt.Fprintf(&sb, "%s = $%d, ", Identifier{column}.Sanitize(), len(args))
+	}
+	args = append(args, key, version)
+	fmt.Fprintf(&sb, "%[1]s = %[1]s + 1 where %[2]s = $%[3]d and %[1]s = $%[4]d returning %[1]s", quotedVersion, quotedKey, len(args)-1, len(args))
+
+	var newVersion int64
+	err := db.QueryRow(ctx, sb.String(), args...).Scan(&newVersion)
+	if err == nil {
+		return newVersion, nil
+	}
+	if !errors.Is(err, ErrNoRows) {
+		return 0, err
+	}
+
+	// No row was updated. Distinguish a stale version from a missing row.
+	var exists bool
+	err = db.QueryRow(ctx, "select exists(select 1 from "+quotedTable+" where "+quotedKey+" = $1)", key).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, ErrConcurrentModification
+	}
+	return 0, ErrNoRows
+}
@@ -0,0 +1,41 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateWithVersion(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table update_with_version (id int8 primary key, name text not null, qty int4 not null, version int8 not null);
+insert into update_with_version values (1, 'a', 1, 1);`)
+
+		table := pgx.Identifier{"update_with_version"}
+
+		version, err := pgx.UpdateWithVersion(ctx, conn, table, "id", int64(1), "version", 1, map[string]any{"name": "b", "qty": 2})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, version)
+
+		var name string
+		var qty int32
+		err = conn.QueryRow(ctx, "select name, qty from update_with_version where id = 1").Scan(&name, &qty)
+		require.NoError(t, err)
+		require.Equal(t, "b", name)
+		require.EqualValues(t, 2, qty)
+
+		_, err = pgx.UpdateWithVersion(ctx, conn, table, "id", int64(1), "version", 1, map[string]any{"name": "c"})
+		require.ErrorIs(t, err, pgx.ErrConcurrentModification)
+
+		_, err = pgx.UpdateWithVersion(ctx, conn, table, "id", int64(2), "version", 1, map[string]any{"name": "c"})
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		_, err = pgx.UpdateWithVersion(ctx, conn, table, "id", int64(1), "version", 2, map[string]any{"version": 5})
+		require.Error(t, err)
+	})
+}
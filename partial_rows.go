@@ -0,0 +1,113 @@
+package pgx
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PartialRows is the result of QueryPartial. It implements Rows and reads the rows of a query until a deadline. Rows
+// produced after the deadline are not read and Truncated reports whether any rows were left unread.
+type PartialRows struct {
+	chunkedRows *ChunkedRows
+	deadline    time.Time
+	fetched     bool
+	truncated   bool
+}
+
+// QueryPartial executes sql with args and returns the rows the server produces before deadline. Reaching the deadline
+// is not an error. Instead, the query is ended and Truncated reports true. This is useful for latency bounded reads
+// where partial data is better than none, such as a dashboard.
+//
+// Rows are fetched chunkSize rows at a time by executing the unnamed portal with a row limit (see QueryChunked). No
+// more chunks are fetched once the deadline has passed. If the server is still producing the rows of a chunk at the
+// deadline a cancel request is sent and the rows it produced before the cancellation are still returned. If the chunk
+// is complete before the server acts on the cancel request the connection is closed when the rows are closed, because
+// the cancel request could otherwise cancel a later query on the connection.
+func (c *Conn) QueryPartial(ctx context.Context, deadline time.Time, chunkSize int, sql string, args ...any) (*PartialRows, error) {
+	chunkedRows, err := c.QueryChunked(ctx, chunkSize, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	chunkedRows.portalReader.SetCancelDeadline(deadline)
+
+	return &PartialRows{chunkedRows: chunkedRows, deadline: deadline}, nil
+}
+
+// Truncated returns true if the deadline passed before all rows were read.
+func (rows *PartialRows) Truncated() bool {
+	return rows.truncated
+}
+
+// Next prepares the next row for reading, fetching another chunk of rows if the current chunk is exhausted and the
+// deadline has not passed. It returns false when all rows have been read, the deadline has passed, or an error
+// occurred. The rows are closed automatically when Next returns false.
+func (rows *PartialRows) Next() bool {
+	for {
+		if rows.chunkedRows.Next() {
+			return true
+		}
+
+		if rows.chunkedRows.portalReader.Canceled() {
+			rows.truncated = true
+			rows.Close()
+			return false
+		}
+
+		if rows.chunkedRows.closed {
+			return false
+		}
+
+		if rows.fetched && !time.Now().Before(rows.deadline) {
+			rows.truncated = rows.chunkedRows.portalReader.Suspended()
+			rows.Close()
+			return false
+		}
+
+		if !rows.chunkedRows.FetchMore() {
+			return false
+		}
+		rows.fetched = true
+	}
+}
+
+// Close closes the rows, discarding any remaining rows and making the connection ready for use again.
+func (rows *PartialRows) Close() {
+	rows.chunkedRows.Close()
+}
+
+// Err returns any error that occurred while reading. Reaching the deadline is not an error.
+func (rows *PartialRows) Err() error {
+	return rows.chunkedRows.Err()
+}
+
+// CommandTag returns the command tag reported for the last chunk. It is empty if the rows were truncated.
+func (rows *PartialRows) CommandTag() pgconn.CommandTag {
+	return rows.chunkedRows.CommandTag()
+}
+
+// FieldDescriptions returns the field descriptions of the result.
+func (rows *PartialRows) FieldDescriptions() []pgconn.FieldDescription {
+	return rows.chunkedRows.FieldDescriptions()
+}
+
+// Scan reads the values from the current row into dest values positionally.
+func (rows *PartialRows) Scan(dest ...any) error {
+	return rows.chunkedRows.Scan(dest...)
+}
+
+// Values returns the decoded values of the current row.
+func (rows *PartialRows) Values() ([]any, error) {
+	return rows.chunkedRows.Values()
+}
+
+// RawValues returns the unparsed bytes of the current row.
+func (rows *PartialRows) RawValues() [][]byte {
+	return rows.chunkedRows.RawValues()
+}
+
+// Conn returns the underlying *Conn.
+func (rows *PartialRows) Conn() *Conn {
+	return rows.chunkedRows.Conn()
+}
@@ -0,0 +1,63 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnQueryPartial(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.QueryPartial(ctx, time.Now().Add(time.Minute), 3, "select n from generate_series(1, $1::int4) n", 10)
+		require.NoError(t, err)
+
+		ns, err := pgx.CollectRows[int32](rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ns)
+		require.False(t, rows.Truncated())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryPartialTruncated(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.QueryPartial(ctx, time.Now().Add(200*time.Millisecond), 1, "select n from generate_series(1, 100) n where pg_sleep(0.05)::text = ''")
+		require.NoError(t, err)
+
+		ns, err := pgx.CollectRows[int32](rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.NotEmpty(t, ns)
+		require.Less(t, len(ns), 100)
+		require.True(t, rows.Truncated())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryPartialCancelsChunkAtDeadline(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		start := time.Now()
+
+		// The server sleeps while producing the third row of the first chunk.
+		rows, err := conn.QueryPartial(ctx, time.Now().Add(500*time.Millisecond), 10, "select n from generate_series(1, 5) n where pg_sleep(case when n = 3 then 10 else 0 end)::text = ''")
+		require.NoError(t, err)
+
+		ns, err := pgx.CollectRows[int32](rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2}, ns)
+		require.True(t, rows.Truncated())
+		require.Less(t, time.Since(start), 5*time.Second)
+
+		ensureConnValid(t, conn)
+	})
+}
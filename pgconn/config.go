@@ -41,6 +41,21 @@ type Config struct {
 	BuildFrontend  BuildFrontendFunc
 	RuntimeParams  map[string]string // Run-time parameters to set on connection as session default values (e.g. search_path or application_name)
 
+	// TCPKeepAlive is the keep-alive period applied to TCP connections after they are established. Zero leaves the
+	// socket as configured by DialFunc. A negative value disables keep-alives. It can be set in the connection string
+	// with keepalives_idle (in seconds) or disabled with keepalives=0.
+	TCPKeepAlive time.Duration
+
+	// MaxRowBytes is the maximum size in bytes of a single row received from the server. If a larger row is received
+	// the connection is closed and an error wrapping ErrRowTooLarge is returned without buffering the row. Zero means no
+	// limit.
+	MaxRowBytes int
+
+	// ProtocolVersion is the protocol version sent in the startup message, encoded as major<<16 | minor. Zero means
+	// pgproto3.ProtocolVersionNumber. ConnectConfig returns an error before connecting if pgx cannot use the version.
+	// Pinning the version is useful when testing against proxies that only understand a specific version.
+	ProtocolVersion uint32
+
 	KerberosSrvName string
 	KerberosSpn     string
 	Fallbacks       []*FallbackConfig
@@ -63,6 +78,19 @@ type Config struct {
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
+// validateProtocolVersion returns an error if pgx cannot speak protocol version v. pgx requires protocol 3 for the
+// extended query protocol and pipelining, and does not implement the cancel key changes of minor versions after 3.0.
+func validateProtocolVersion(v uint32) error {
+	major, minor := v>>16, v&0xffff
+	if major != 3 {
+		return fmt.Errorf("protocol version %d.%d is not supported: the extended query protocol and pipelining require protocol version 3", major, minor)
+	}
+	if v > pgproto3.ProtocolVersionNumber {
+		return fmt.Errorf("protocol version %d.%d is not supported: the latest supported version is 3.0", major, minor)
+	}
+	return nil
+}
+
 // ParseConfigOptions contains options that control how a config is built such as GetSSLPassword.
 type ParseConfigOptions struct {
 	// GetSSLPassword gets the password to decrypt a SSL client certificate. This is analogous to the the libpq function
@@ -213,6 +241,9 @@ func NetworkAddress(host string, port uint16) (network, address string) {
 //   - servicefile.
 //     libpq only reads servicefile from the PGSERVICEFILE environment variable. ParseConfig accepts servicefile as a
 //     part of the connection string.
+//
+// keepalives and keepalives_idle are supported and set TCPKeepAlive. keepalives_interval and keepalives_count are not
+// supported as the Go standard library uses the same period for the idle time and the probe interval.
 func ParseConfig(connString string) (*Config, error) {
 	var parseConfigOptions ParseConfigOptions
 	return ParseConfigWithOptions(connString, parseConfigOptions)
@@ -277,6 +308,23 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 
 	config.LookupFunc = makeDefaultResolver().LookupHost
 
+	if keepAliveSetting, present := settings["keepalives_idle"]; present {
+		keepAlive, err := parseKeepAliveSetting(keepAliveSetting)
+		if err != nil {
+			return nil, &parseConfigError{connString: connString, msg: "invalid keepalives_idle", err: err}
+		}
+		config.TCPKeepAlive = keepAlive
+	}
+	if keepAlivesSetting, present := settings["keepalives"]; present {
+		switch keepAlivesSetting {
+		case "0":
+			config.TCPKeepAlive = -1
+		case "1":
+		default:
+			return nil, &parseConfigError{connString: connString, msg: "invalid keepalives", err: errors.New("must be 0 or 1")}
+		}
+	}
+
 	notRuntimeParams := map[string]struct{}{
 		"host":                 {},
 		"port":                 {},
@@ -285,6 +333,8 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 		"password":             {},
 		"passfile":             {},
 		"connect_timeout":      {},
+		"keepalives":           {},
+		"keepalives_idle":      {},
 		"sslmode":              {},
 		"sslkey":               {},
 		"sslcert":              {},
@@ -803,6 +853,17 @@ func parseConnectTimeoutSetting(s string) (time.Duration, error) {
 	return time.Duration(timeout) * time.Second, nil
 }
 
+func parseKeepAliveSetting(s string) (time.Duration, error) {
+	keepAlive, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if keepAlive < 0 {
+		return 0, errors.New("negative keepalive")
+	}
+	return time.Duration(keepAlive) * time.Second, nil
+}
+
 func makeConnectTimeoutDialFunc(timeout time.Duration) DialFunc {
 	d := makeDefaultDialer()
 	d.Timeout = timeout
@@ -10,6 +10,14 @@ import (
 	"strings"
 )
 
+// ErrRowTooLarge occurs when a row received from the server is larger than Config.MaxRowBytes.
+var ErrRowTooLarge = errors.New("row too large")
+
+// ErrUnexpectedMessage occurs when the server sends a message that cannot be handled while reading query results. For
+// example, a COPY FROM STDIN sent with Exec would wait for copy data that is never sent. The connection is closed as it
+// can no longer be kept in sync with the server.
+var ErrUnexpectedMessage = errors.New("unexpected message")
+
 // SafeToRetry checks if the err is guaranteed to have occurred before sending any data to the server.
 func SafeToRetry(err error) bool {
 	if e, ok := err.(interface{ SafeToRetry() bool }); ok {
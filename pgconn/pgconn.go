@@ -125,6 +125,12 @@ func ConnectConfig(octx context.Context, config *Config) (pgConn *PgConn, err er
 		panic("config must be created by ParseConfig")
 	}
 
+	if config.ProtocolVersion != 0 {
+		if err := validateProtocolVersion(config.ProtocolVersion); err != nil {
+			return nil, &connectError{config: config, msg: "invalid protocol version", err: err}
+		}
+	}
+
 	// Simplify usage by treating primary config and fallbacks the same.
 	fallbackConfigs := []*FallbackConfig{
 		{
@@ -254,6 +260,17 @@ func expandWithIPs(ctx context.Context, lookupFn LookupFunc, fallbacks []*Fallba
 	return configs, nil
 }
 
+func setTCPKeepAlive(conn *net.TCPConn, period time.Duration) error {
+	if period < 0 {
+		return conn.SetKeepAlive(false)
+	}
+	err := conn.SetKeepAlive(true)
+	if err != nil {
+		return err
+	}
+	return conn.SetKeepAlivePeriod(period)
+}
+
 func connect(ctx context.Context, config *Config, fallbackConfig *FallbackConfig,
 	ignoreNotPreferredErr bool) (*PgConn, error) {
 	pgConn := new(PgConn)
@@ -266,6 +283,15 @@ func connect(ctx context.Context, config *Config, fallbackConfig *FallbackConfig
 	if err != nil {
 		return nil, &connectError{config: config, msg: "dial error", err: normalizeTimeoutError(ctx, err)}
 	}
+	if config.TCPKeepAlive != 0 {
+		if tcpConn, ok := netConn.(*net.TCPConn); ok {
+			err = setTCPKeepAlive(tcpConn, config.TCPKeepAlive)
+			if err != nil {
+				netConn.Close()
+				return nil, &connectError{config: config, msg: "failed to set tcp keepalive", err: err}
+			}
+		}
+	}
 	nbNetConn := nbconn.NewNetConn(netConn, false)
 
 	pgConn.conn = nbNetConn
@@ -290,11 +316,17 @@ func connect(ctx context.Context, config *Config, fallbackConfig *FallbackConfig
 	pgConn.parameterStatuses = make(map[string]string)
 	pgConn.status = connStatusConnecting
 	pgConn.frontend = config.BuildFrontend(pgConn.conn, pgConn.conn)
+	if config.MaxRowBytes > 0 {
+		pgConn.frontend.SetMaxDataRowLen(config.MaxRowBytes)
+	}
 
 	startupMsg := pgproto3.StartupMessage{
 		ProtocolVersion: pgproto3.ProtocolVersionNumber,
 		Parameters:      make(map[string]string),
 	}
+	if config.ProtocolVersion != 0 {
+		startupMsg.ProtocolVersion = config.ProtocolVersion
+	}
 
 	// Copy default run-time params
 	for k, v := range config.RuntimeParams {
@@ -472,6 +504,11 @@ func (pgConn *PgConn) peekMessage() (pgproto3.BackendMessage, error) {
 			return nil, err
 		}
 
+		var maxDataRowLenErr *pgproto3.ExceededMaxDataRowLenErr
+		if errors.As(err, &maxDataRowLenErr) {
+			err = fmt.Errorf("%w: %v", ErrRowTooLarge, err)
+		}
+
 		// Close on anything other than timeout error - everything else is fatal
 		var netErr net.Error
 		isNetErr := errors.As(err, &netErr)
@@ -994,7 +1031,24 @@ func (pgConn *PgConn) ExecParams(ctx context.Context, sql string, paramValues []
 	pgConn.frontend.SendParse(&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs})
 	pgConn.frontend.SendBind(&pgproto3.Bind{ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
 
-	pgConn.execExtendedSuffix(result)
+	pgConn.execExtendedSuffix(result, nil)
+
+	return result
+}
+
+// ExecParamsWithFields is like ExecParams but does not ask the server to describe the result. Instead, fields are used
+// as the field descriptions of the result. The formats of fields must match resultFormats. If fields do not match the
+// result the server returns, the values of the rows will not match their field descriptions.
+func (pgConn *PgConn) ExecParamsWithFields(ctx context.Context, sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16, fields []FieldDescription) *ResultReader {
+	result := pgConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+
+	pgConn.frontend.SendParse(&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs})
+	pgConn.frontend.SendBind(&pgproto3.Bind{ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	pgConn.execExtendedSuffix(result, fields)
 
 	return result
 }
@@ -1019,7 +1073,23 @@ func (pgConn *PgConn) ExecPrepared(ctx context.Context, stmtName string, paramVa
 
 	pgConn.frontend.SendBind(&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
 
-	pgConn.execExtendedSuffix(result)
+	pgConn.execExtendedSuffix(result, nil)
+
+	return result
+}
+
+// ExecPreparedWithFields is like ExecPrepared but does not ask the server to describe the result. Instead, fields are
+// used as the field descriptions of the result. The formats of fields must match resultFormats. If fields do not match
+// the result the server returns, the values of the rows will not match their field descriptions.
+func (pgConn *PgConn) ExecPreparedWithFields(ctx context.Context, stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16, fields []FieldDescription) *ResultReader {
+	result := pgConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+
+	pgConn.frontend.SendBind(&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	pgConn.execExtendedSuffix(result, fields)
 
 	return result
 }
@@ -1059,8 +1129,14 @@ func (pgConn *PgConn) execExtendedPrefix(ctx context.Context, paramValues [][]by
 	return result
 }
 
-func (pgConn *PgConn) execExtendedSuffix(result *ResultReader) {
-	pgConn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P'})
+// execExtendedSuffix sends the Execute and Sync messages and reads until the result description. If fields is not nil the
+// result is not described by the server and fields are used instead.
+func (pgConn *PgConn) execExtendedSuffix(result *ResultReader, fields []FieldDescription) {
+	if fields == nil {
+		pgConn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P'})
+	} else {
+		result.fieldDescriptions = fields
+	}
 	pgConn.frontend.SendExecute(&pgproto3.Execute{})
 	pgConn.frontend.SendSync(&pgproto3.Sync{})
 
@@ -1283,6 +1359,9 @@ func (mrr *MultiResultReader) ReadAll() ([]*Result, error) {
 
 func (mrr *MultiResultReader) receiveMessage() (pgproto3.BackendMessage, error) {
 	msg, err := mrr.pgConn.receiveMessage()
+	if err == nil {
+		err = checkResultMessage(msg)
+	}
 
 	if err != nil {
 		mrr.pgConn.contextWatcher.Unwatch()
@@ -1308,6 +1387,16 @@ func (mrr *MultiResultReader) receiveMessage() (pgproto3.BackendMessage, error)
 	return msg, nil
 }
 
+// checkResultMessage returns an error if msg cannot be handled while reading query results. Such a message means the
+// connection is out of sync with the server and must be closed.
+func checkResultMessage(msg pgproto3.BackendMessage) error {
+	switch msg.(type) {
+	case *pgproto3.CopyInResponse, *pgproto3.CopyBothResponse:
+		return fmt.Errorf("%w: %T", ErrUnexpectedMessage, msg)
+	}
+	return nil
+}
+
 // NextResult returns advances the MultiResultReader to the next result and returns true if a result is available.
 func (mrr *MultiResultReader) NextResult() bool {
 	for !mrr.closed && mrr.err == nil {
@@ -1497,6 +1586,9 @@ func (rr *ResultReader) readUntilRowDescription() {
 func (rr *ResultReader) receiveMessage() (msg pgproto3.BackendMessage, err error) {
 	if rr.multiResultReader == nil {
 		msg, err = rr.pgConn.receiveMessage()
+		if err == nil {
+			err = checkResultMessage(msg)
+		}
 	} else {
 		msg, err = rr.multiResultReader.receiveMessage()
 	}
@@ -1796,6 +1888,32 @@ func (p *Pipeline) SendQueryParams(sql string, paramValues [][]byte, paramOIDs [
 	p.conn.frontend.SendExecute(&pgproto3.Execute{})
 }
 
+// SendCopyFrom is the pipeline version of *PgConn.CopyFrom. sql must be a COPY ... FROM STDIN statement without
+// parameters. data is the entire copy data. It is sent immediately after the statement so it does not wait for the
+// server to enter copy mode. If an error occurs the server discards the remaining data along with the rest of the
+// messages until the next Sync. The result is a *ResultReader with the command tag of the COPY.
+func (p *Pipeline) SendCopyFrom(sql string, data []byte) {
+	if p.closed {
+		return
+	}
+	p.pendingSync = true
+
+	p.conn.frontend.SendParse(&pgproto3.Parse{Query: sql})
+	p.conn.frontend.SendBind(&pgproto3.Bind{})
+	p.conn.frontend.SendExecute(&pgproto3.Execute{})
+
+	const maxCopyDataLen = 65536 - 5 // The message has a 5-byte header
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > maxCopyDataLen {
+			chunk = chunk[:maxCopyDataLen]
+		}
+		data = data[len(chunk):]
+		p.conn.frontend.Send(&pgproto3.CopyData{Data: chunk})
+	}
+	p.conn.frontend.Send(&pgproto3.CopyDone{})
+}
+
 // SendQueryPrepared is the pipeline version of *PgConn.QueryPrepared.
 func (p *Pipeline) SendQueryPrepared(stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16) {
 	if p.closed {
@@ -1835,6 +1953,13 @@ func (p *Pipeline) Flush() error {
 
 // Sync establishes a synchronization point and flushes the queued requests.
 func (p *Pipeline) Sync() error {
+	if p.closed {
+		if p.err != nil {
+			return p.err
+		}
+		return errors.New("pipeline closed")
+	}
+
 	p.conn.frontend.SendSync(&pgproto3.Sync{})
 	err := p.Flush()
 	if err != nil {
@@ -1858,7 +1983,8 @@ func (p *Pipeline) GetResults() (results any, err error) {
 	for {
 		msg, err := p.conn.receiveMessage()
 		if err != nil {
-			return nil, err
+			p.conn.asyncClose()
+			return nil, normalizeTimeoutError(p.ctx, err)
 		}
 
 		switch msg := msg.(type) {
@@ -159,6 +159,24 @@ func TestConnectTLSPasswordProtectedClientCertWithGetSSLPasswordConfigOption(t *
 	closeConn(t, conn)
 }
 
+func TestConnectUnsupportedProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []uint32{2 << 16, 3<<16 | 2, 1234<<16 | 5679} {
+		config, err := pgconn.ParseConfig("host=localhost")
+		require.NoError(t, err)
+		config.ProtocolVersion = version
+		config.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			t.Error("unexpected dial")
+			return nil, errors.New("unexpected dial")
+		}
+
+		conn, err := pgconn.ConnectConfig(context.Background(), config)
+		require.ErrorContains(t, err, "invalid protocol version")
+		require.Nil(t, conn)
+	}
+}
+
 type pgmockWaitStep time.Duration
 
 func (s pgmockWaitStep) Step(*pgproto3.Backend) error {
@@ -832,6 +850,142 @@ func TestConnExecParams(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnExecParamsWithFields(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	fields := []pgconn.FieldDescription{{Name: "expected", DataTypeOID: 25, Format: 0}}
+	result := pgConn.ExecParamsWithFields(context.Background(), "select $1::text as msg", [][]byte{[]byte("Hello, world")}, nil, nil, []int16{0}, fields)
+	require.Equal(t, fields, result.FieldDescriptions())
+
+	rowCount := 0
+	for result.NextRow() {
+		rowCount += 1
+		assert.Equal(t, "Hello, world", string(result.Values()[0]))
+	}
+	assert.Equal(t, 1, rowCount)
+	commandTag, err := result.Close()
+	assert.Equal(t, "SELECT 1", commandTag.String())
+	assert.NoError(t, err)
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecParamsPortal(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	pr := pgConn.ExecParamsPortal(context.Background(), "select n::text as n from generate_series(1, 5) n", nil, nil, nil, nil, 2)
+	require.NoError(t, pr.Err())
+	require.Len(t, pr.FieldDescriptions(), 1)
+	assert.Equal(t, "n", pr.FieldDescriptions()[0].Name)
+
+	var chunks [][]string
+	for pr.FetchMore() {
+		var chunk []string
+		for pr.NextRow() {
+			chunk = append(chunk, string(pr.Values()[0]))
+		}
+		chunks = append(chunks, chunk)
+	}
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, chunks)
+
+	commandTag, err := pr.Close()
+	require.NoError(t, err)
+	assert.True(t, commandTag.Select())
+
+	pr = pgConn.ExecParamsPortal(context.Background(), "select 1 from missing_table", nil, nil, nil, nil, 2)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, pr.Err(), &pgErr)
+	assert.Equal(t, "42P01", pgErr.Code)
+	require.False(t, pr.FetchMore())
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecParamsPortalCancelDeadline(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	pr := pgConn.ExecParamsPortal(context.Background(), "select n::text from generate_series(1, 5) n where pg_sleep(case when n = 3 then 10 else 0 end)::text = ''", nil, nil, nil, nil, 10)
+	require.NoError(t, pr.Err())
+	pr.SetCancelDeadline(time.Now().Add(500 * time.Millisecond))
+
+	var values []string
+	require.True(t, pr.FetchMore())
+	for pr.NextRow() {
+		values = append(values, string(pr.Values()[0]))
+	}
+	assert.Equal(t, []string{"1", "2"}, values)
+	assert.True(t, pr.Canceled())
+	require.False(t, pr.FetchMore())
+
+	_, err = pr.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecParamsMaxRowBytes(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxRowBytes = 1024
+
+	pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	result := pgConn.ExecParams(context.Background(), "select repeat('x', n) from unnest(array[10, 10000]) n", nil, nil, nil, nil)
+	require.True(t, result.NextRow())
+	assert.Equal(t, strings.Repeat("x", 10), string(result.Values()[0]))
+	require.False(t, result.NextRow())
+	_, err = result.Close()
+	require.ErrorIs(t, err, pgconn.ErrRowTooLarge)
+
+	select {
+	case <-pgConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
+	require.True(t, pgConn.IsClosed())
+}
+
+func TestConnExecCopyFromStdinClosesConn(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support COPY FROM STDIN in a multi-statement query")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = pgConn.Exec(ctx, "create temporary table foo(a int4); copy foo from stdin").ReadAll()
+	require.ErrorIs(t, err, pgconn.ErrUnexpectedMessage)
+
+	select {
+	case <-pgConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
+	require.True(t, pgConn.IsClosed())
+}
+
 func TestConnExecParamsDeferredError(t *testing.T) {
 	t.Parallel()
 
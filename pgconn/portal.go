@@ -0,0 +1,324 @@
+package pgconn
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+// PortalReader reads the result of a query whose rows are fetched in chunks by executing the unnamed portal with a row
+// limit. The portal is suspended after each chunk until the next chunk is requested with FetchMore. It is created by
+// ExecParamsPortal.
+//
+// The entire query runs in a single implicit transaction that is not committed until the PortalReader is closed.
+// PortalReader must be closed before PgConn can be used again.
+type PortalReader struct {
+	pgConn            *PgConn
+	ctx               context.Context
+	maxRows           uint32
+	fieldDescriptions []FieldDescription
+	rowValues         [][]byte
+	commandTag        CommandTag
+
+	executing bool // rows of the current Execute may still be received
+	suspended bool // the portal has more rows
+	executed  bool // the portal has been executed at least once
+	closed    bool
+	err       error
+
+	cancelDeadline  time.Time
+	readDeadlineSet bool // the read deadline of the connection is set to cancelDeadline
+	cancelRequested bool // a cancel request was sent because cancelDeadline passed
+	canceled        bool // the server canceled the portal in response to the cancel request
+}
+
+// ExecParamsPortal binds sql to the unnamed portal without executing it. Rows are fetched maxRows at a time by calling
+// FetchMore and NextRow. maxRows must be greater than 0. See ExecParams for a description of the other arguments.
+//
+// Errors parsing or binding the query are returned by Err. The PortalReader is already closed in that case.
+func (pgConn *PgConn) ExecParamsPortal(ctx context.Context, sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16, maxRows uint32) *PortalReader {
+	return pgConn.execPortal(
+		ctx,
+		&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs},
+		&pgproto3.Bind{ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats},
+		maxRows,
+	)
+}
+
+// ExecPreparedPortal binds the prepared statement stmtName to the unnamed portal without executing it. It is like
+// ExecParamsPortal but the statement is not parsed again. See ExecPrepared for a description of the other arguments.
+func (pgConn *PgConn) ExecPreparedPortal(ctx context.Context, stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16, maxRows uint32) *PortalReader {
+	return pgConn.execPortal(
+		ctx,
+		nil,
+		&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats},
+		maxRows,
+	)
+}
+
+// execPortal sends parse, if it is not nil, and bind and reads the description of the portal.
+func (pgConn *PgConn) execPortal(ctx context.Context, parse *pgproto3.Parse, bind *pgproto3.Bind, maxRows uint32) *PortalReader {
+	pr := &PortalReader{pgConn: pgConn, ctx: ctx, maxRows: maxRows}
+
+	result := pgConn.execExtendedPrefix(ctx, bind.Parameters)
+	if result.closed {
+		pr.err = result.err
+		pr.closed = true
+		return pr
+	}
+
+	if parse != nil {
+		pgConn.frontend.SendParse(parse)
+	}
+	pgConn.frontend.SendBind(bind)
+	pgConn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P'})
+	pgConn.frontend.Send(&pgproto3.Flush{})
+	if !pr.flush() {
+		return pr
+	}
+
+	for {
+		msg, ok := pr.receiveMessage()
+		if !ok {
+			return pr
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.RowDescription:
+			pr.fieldDescriptions = pgConn.convertRowDescription(make([]FieldDescription, 0, len(msg.Fields)), msg)
+			return pr
+		case *pgproto3.NoData:
+			return pr
+		case *pgproto3.ErrorResponse:
+			pr.err = ErrorResponseToPgError(msg)
+			pr.Close()
+			return pr
+		}
+	}
+}
+
+// FieldDescriptions returns the field descriptions of the result.
+func (pr *PortalReader) FieldDescriptions() []FieldDescription {
+	return pr.fieldDescriptions
+}
+
+// FetchMore requests the next chunk of rows. Any rows of the previous chunk that have not been read are discarded. It
+// returns false when all rows have been fetched or an error occurred.
+func (pr *PortalReader) FetchMore() bool {
+	if pr.closed {
+		return false
+	}
+
+	for pr.NextRow() {
+	}
+
+	if pr.err != nil || (pr.executed && !pr.suspended) {
+		return false
+	}
+
+	pr.pgConn.frontend.SendExecute(&pgproto3.Execute{MaxRows: pr.maxRows})
+	pr.pgConn.frontend.Send(&pgproto3.Flush{})
+	if !pr.flush() {
+		return false
+	}
+
+	pr.executed = true
+	pr.executing = true
+	pr.suspended = false
+
+	if !pr.cancelDeadline.IsZero() && !pr.cancelRequested {
+		if err := pr.pgConn.conn.SetReadDeadline(pr.cancelDeadline); err != nil {
+			pr.fail(err)
+			return false
+		}
+		pr.readDeadlineSet = true
+	}
+
+	return true
+}
+
+// SetCancelDeadline sets a time at which a cancel request is sent to the server if it is still producing the rows of
+// a chunk. The rows received before the server canceled the portal are still returned by NextRow and the cancellation
+// is not an error. Instead, Canceled returns true and no more chunks can be fetched. If the cancel request cannot be
+// delivered the rest of the chunk is read. The deadline applies to chunks fetched after SetCancelDeadline is called.
+// The zero value means there is no deadline.
+//
+// If the chunk is complete before the server acts on the cancel request, the cancel request could cancel a later query
+// on the connection. In that case Close closes the connection instead of making it ready for use again.
+func (pr *PortalReader) SetCancelDeadline(t time.Time) {
+	pr.cancelDeadline = t
+}
+
+// Canceled returns true if the portal was canceled because the deadline set by SetCancelDeadline passed.
+func (pr *PortalReader) Canceled() bool {
+	return pr.canceled
+}
+
+// NextRow advances to the next row of the current chunk and returns true if a row is available.
+func (pr *PortalReader) NextRow() bool {
+	for pr.executing {
+		msg, ok := pr.receiveMessage()
+		if !ok {
+			return false
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.DataRow:
+			pr.rowValues = msg.Values
+			return true
+		case *pgproto3.PortalSuspended:
+			pr.executing = false
+			pr.suspended = true
+		case *pgproto3.CommandComplete:
+			pr.executing = false
+			pr.commandTag = pr.pgConn.makeCommandTag(msg.CommandTag)
+		case *pgproto3.EmptyQueryResponse:
+			pr.executing = false
+		case *pgproto3.ErrorResponse:
+			pr.executing = false
+			pgErr := ErrorResponseToPgError(msg)
+			if pr.cancelRequested && pgErr.Code == "57014" { // query_canceled
+				pr.canceled = true
+			} else {
+				pr.err = pgErr
+			}
+		}
+	}
+
+	pr.clearReadDeadline()
+	pr.rowValues = nil
+	return false
+}
+
+// Values returns the current row data. NextRow must have been previously been called. The returned [][]byte is only
+// valid until the next NextRow call or the PortalReader is closed.
+func (pr *PortalReader) Values() [][]byte {
+	return pr.rowValues
+}
+
+// Suspended returns true if the portal has more rows to fetch after the current chunk.
+func (pr *PortalReader) Suspended() bool {
+	return pr.suspended
+}
+
+// Err returns any error that has occurred.
+func (pr *PortalReader) Err() error {
+	return pr.err
+}
+
+// Close discards any unread rows, ends the implicit transaction, and returns the command tag or error. The command tag
+// is only available if all rows were fetched.
+func (pr *PortalReader) Close() (CommandTag, error) {
+	if pr.closed {
+		return pr.commandTag, pr.err
+	}
+
+	for pr.NextRow() {
+	}
+	if pr.closed {
+		return pr.commandTag, pr.err
+	}
+
+	// A cancel request that the server has not acted on yet could cancel the next query on the connection.
+	if pr.cancelRequested && !pr.canceled {
+		pr.closed = true
+		pr.pgConn.asyncClose()
+		pr.pgConn.contextWatcher.Unwatch()
+		pr.pgConn.unlock()
+		return pr.commandTag, pr.err
+	}
+
+	pr.pgConn.frontend.SendSync(&pgproto3.Sync{})
+	if !pr.flush() {
+		return pr.commandTag, pr.err
+	}
+
+	for {
+		msg, ok := pr.receiveMessage()
+		if !ok {
+			return pr.commandTag, pr.err
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			if pr.err == nil {
+				pr.err = ErrorResponseToPgError(msg)
+			}
+		case *pgproto3.ReadyForQuery:
+			pr.closed = true
+			pr.pgConn.contextWatcher.Unwatch()
+			pr.pgConn.unlock()
+			return pr.commandTag, pr.err
+		}
+	}
+}
+
+func (pr *PortalReader) flush() bool {
+	err := pr.pgConn.frontend.Flush()
+	if err != nil {
+		pr.fail(normalizeTimeoutError(pr.ctx, err))
+		return false
+	}
+	return true
+}
+
+func (pr *PortalReader) receiveMessage() (pgproto3.BackendMessage, bool) {
+	for {
+		msg, err := pr.pgConn.receiveMessage()
+		if err == nil {
+			err = checkResultMessage(msg)
+		}
+		if err != nil {
+			if pr.cancelAtDeadline(err) {
+				continue
+			}
+			pr.fail(normalizeTimeoutError(pr.ctx, err))
+			return nil, false
+		}
+
+		return msg, true
+	}
+}
+
+// cancelAtDeadline sends a cancel request if err is the read timeout caused by the cancel deadline. It returns true if
+// reading should continue.
+func (pr *PortalReader) cancelAtDeadline(err error) bool {
+	var netErr net.Error
+	if !pr.readDeadlineSet || pr.ctx.Err() != nil || !(errors.As(err, &netErr) && netErr.Timeout()) {
+		return false
+	}
+
+	pr.cancelRequested = true
+	pr.clearReadDeadline()
+	if pr.readDeadlineSet {
+		return false
+	}
+
+	// Errors are ignored. If the cancel request is not delivered the server finishes the chunk normally.
+	pr.pgConn.CancelRequest(pr.ctx)
+	return true
+}
+
+// clearReadDeadline removes the read deadline set for the cancel deadline.
+func (pr *PortalReader) clearReadDeadline() {
+	if !pr.readDeadlineSet || pr.pgConn.conn.SetReadDeadline(time.Time{}) != nil {
+		return
+	}
+	pr.readDeadlineSet = false
+}
+
+// fail closes the connection after a fatal error.
+func (pr *PortalReader) fail(err error) {
+	if pr.err == nil {
+		pr.err = err
+	}
+	pr.executing = false
+	pr.rowValues = nil
+	pr.closed = true
+	pr.pgConn.asyncClose()
+	pr.pgConn.contextWatcher.Unwatch()
+	pr.pgConn.unlock()
+}
@@ -52,10 +52,11 @@ type Frontend struct {
 	rowDescription                  RowDescription
 	portalSuspended                 PortalSuspended
 
-	bodyLen    int
-	msgType    byte
-	partialMsg bool
-	authType   uint32
+	bodyLen       int
+	maxDataRowLen int
+	msgType       byte
+	partialMsg    bool
+	authType      uint32
 }
 
 // NewFrontend creates a new Frontend.
@@ -64,6 +65,13 @@ func NewFrontend(r io.Reader, w io.Writer) *Frontend {
 	return &Frontend{cr: cr, w: w}
 }
 
+// SetMaxDataRowLen sets the maximum length of a DataRow message body. If a larger DataRow is received Receive returns
+// an *ExceededMaxDataRowLenErr without reading the message body. The Frontend cannot be used after this error. A value
+// of 0 (the default) disables the limit.
+func (f *Frontend) SetMaxDataRowLen(maxDataRowLen int) {
+	f.maxDataRowLen = maxDataRowLen
+}
+
 // Send sends a message to the backend (i.e. the server). The message is not guaranteed to be written until Flush is
 // called.
 //
@@ -229,6 +237,10 @@ func (f *Frontend) Receive() (BackendMessage, error) {
 			return nil, fmt.Errorf("invalid message length: %d", msgLength)
 		}
 
+		if f.msgType == 'D' && f.maxDataRowLen > 0 && msgLength-4 > f.maxDataRowLen {
+			return nil, &ExceededMaxDataRowLenErr{MaxExpectedLen: f.maxDataRowLen, ActualLen: msgLength - 4}
+		}
+
 		f.bodyLen = msgLength - 4
 		f.partialMsg = true
 	}
@@ -115,3 +115,20 @@ func TestErrorResponse(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, want, got)
 }
+
+func TestFrontendReceiveExceededMaxDataRowLen(t *testing.T) {
+	t.Parallel()
+
+	server := &interruptReader{}
+	server.push([]byte{'D', 0, 0, 0, 10, 0, 1, 0, 0, 0, 0})
+
+	frontend := pgproto3.NewFrontend(server, nil)
+	frontend.SetMaxDataRowLen(5)
+
+	msg, err := frontend.Receive()
+	require.Nil(t, msg)
+	var maxErr *pgproto3.ExceededMaxDataRowLenErr
+	require.ErrorAs(t, err, &maxErr)
+	require.Equal(t, 5, maxErr.MaxExpectedLen)
+	require.Equal(t, 6, maxErr.ActualLen)
+}
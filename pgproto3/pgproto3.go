@@ -53,6 +53,17 @@ func (e *invalidMessageFormatErr) Error() string {
 	return fmt.Sprintf("%s body is invalid %s", e.messageType, e.details)
 }
 
+// ExceededMaxDataRowLenErr is returned by Frontend.Receive when a DataRow message body is larger than the maximum set
+// with SetMaxDataRowLen.
+type ExceededMaxDataRowLenErr struct {
+	MaxExpectedLen int
+	ActualLen      int
+}
+
+func (e *ExceededMaxDataRowLenErr) Error() string {
+	return fmt.Sprintf("DataRow body length %d exceeds maximum of %d", e.ActualLen, e.MaxExpectedLen)
+}
+
 type writeError struct {
 	err         error
 	safeToRetry bool
@@ -504,8 +504,7 @@ func (w *durationWrapper) ScanInterval(v Interval) error {
 		return fmt.Errorf("cannot scan NULL into *time.Interval")
 	}
 
-	us := int64(v.Months)*microsecondsPerMonth + int64(v.Days)*microsecondsPerDay + v.Microseconds
-	*w = durationWrapper(time.Duration(us) * time.Microsecond)
+	*w = durationWrapper(v.Duration())
 	return nil
 }
 
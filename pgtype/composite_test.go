@@ -127,6 +127,55 @@ create type point3d as (
 	})
 }
 
+func TestCompositeCodecTranscodeArray(t *testing.T) {
+	skipCockroachDB(t, "Server does not support composite types (see https://github.com/cockroachdb/cockroach/issues/27792)")
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		_, err := conn.Exec(ctx, `drop type if exists point3d_array_test;
+
+create type point3d_array_test as (
+	x float8,
+	y float8,
+	z float8
+);`)
+		require.NoError(t, err)
+		defer conn.Exec(ctx, "drop type point3d_array_test")
+
+		for _, typeName := range []string{"point3d_array_test", "_point3d_array_test"} {
+			dt, err := conn.LoadType(ctx, typeName)
+			require.NoError(t, err)
+			conn.TypeMap().RegisterType(dt)
+		}
+
+		formats := []struct {
+			name string
+			code int16
+		}{
+			{name: "TextFormat", code: pgx.TextFormatCode},
+			{name: "BinaryFormat", code: pgx.BinaryFormatCode},
+		}
+
+		for _, format := range formats {
+			input := []point3d{{X: 1, Y: 2, Z: 3}, {X: 4, Y: 5, Z: 6}}
+			var output []point3d
+			err := conn.QueryRow(ctx, "select $1::point3d_array_test[]", pgx.QueryResultFormats{format.code}, input).Scan(&output)
+			require.NoErrorf(t, err, "%v", format.name)
+			require.Equalf(t, input, output, "%v", format.name)
+
+			var outputPtrs []*point3d
+			err = conn.QueryRow(ctx, "select array[row(1,2,3), null]::point3d_array_test[]", pgx.QueryResultFormats{format.code}).Scan(&outputPtrs)
+			require.NoErrorf(t, err, "%v", format.name)
+			require.Equalf(t, []*point3d{{X: 1, Y: 2, Z: 3}, nil}, outputPtrs, "%v", format.name)
+
+			output = nil
+			err = conn.QueryRow(ctx, "select '{}'::point3d_array_test[]", pgx.QueryResultFormats{format.code}).Scan(&output)
+			require.NoErrorf(t, err, "%v", format.name)
+			require.Equalf(t, []point3d{}, output, "%v", format.name)
+		}
+	})
+}
+
 func TestCompositeCodecTranscodeStructWrapper(t *testing.T) {
 	skipCockroachDB(t, "Server does not support composite types (see https://github.com/cockroachdb/cockroach/issues/27792)")
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/internal/pgio"
 )
@@ -42,6 +43,14 @@ func (interval Interval) IntervalValue() (Interval, error) {
 	return interval, nil
 }
 
+// Duration returns interval as a time.Duration. PostgreSQL intervals do not have a fixed length because the lengths of
+// months and days vary. Duration approximates a month as 30 days and a day as 24 hours. This is exact for intervals
+// that only have a microseconds component. The result is undefined if it overflows a time.Duration.
+func (interval Interval) Duration() time.Duration {
+	us := int64(interval.Months)*microsecondsPerMonth + int64(interval.Days)*microsecondsPerDay + interval.Microseconds
+	return time.Duration(us) * time.Microsecond
+}
+
 // Scan implements the database/sql Scanner interface.
 func (interval *Interval) Scan(src any) error {
 	if src == nil {
@@ -136,3 +136,21 @@ func TestIntervalCodec(t *testing.T) {
 		{nil, new(pgtype.Interval), isExpectedEq(pgtype.Interval{})},
 	})
 }
+
+func TestIntervalDuration(t *testing.T) {
+	tests := []struct {
+		interval pgtype.Interval
+		expected time.Duration
+	}{
+		{pgtype.Interval{Microseconds: 1500000, Valid: true}, 1500 * time.Millisecond},
+		{pgtype.Interval{Days: 1, Valid: true}, 24 * time.Hour},
+		{pgtype.Interval{Months: 1, Valid: true}, 30 * 24 * time.Hour},
+		{pgtype.Interval{Months: -1, Days: 2, Microseconds: -3, Valid: true}, -28*24*time.Hour - 3*time.Microsecond},
+	}
+
+	for i, tt := range tests {
+		if d := tt.interval.Duration(); d != tt.expected {
+			t.Errorf("%d. expected %v, got %v", i, tt.expected, d)
+		}
+	}
+}
@@ -128,3 +128,19 @@ func TestJSONCodecClearExistingValueBeforeUnmarshal(t *testing.T) {
 		require.Equal(t, map[string]any{"baz": "quz"}, m)
 	})
 }
+
+func TestJSONCodecScanAggregateIntoSlice(t *testing.T) {
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		for _, aggFunc := range []string{"json_agg", "jsonb_agg"} {
+			var people []person
+			err := conn.QueryRow(ctx, "select "+aggFunc+"(t order by t.age) from (values ('Adam', 30), ('Bill', 40)) t(name, age)").Scan(&people)
+			require.NoError(t, err)
+			require.Equal(t, []person{{Name: "Adam", Age: 30}, {Name: "Bill", Age: 40}}, people)
+		}
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 )
 
+// JSONBCodec is the codec for jsonb. Results are requested in the text format. Parameters are sent in the binary format
+// as the jsonb version byte followed by the JSON text, which the server can read without the text input conversion.
 type JSONBCodec struct{}
 
 func (JSONBCodec) FormatSupported(format int16) bool {
@@ -16,6 +18,10 @@ func (JSONBCodec) PreferredFormat() int16 {
 	return TextFormatCode
 }
 
+func (JSONBCodec) PreferredParamFormat() int16 {
+	return BinaryFormatCode
+}
+
 func (JSONBCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
 	switch format {
 	case BinaryFormatCode:
@@ -2,9 +2,13 @@ package pgtype_test
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJSONBTranscode(t *testing.T) {
@@ -31,3 +35,70 @@ func TestJSONBTranscode(t *testing.T) {
 		{jsonStruct{Name: "Adam", Age: 10}, new(jsonStruct), isExpectedEq(jsonStruct{Name: "Adam", Age: 10})},
 	})
 }
+
+func TestJSONBCodecEncodeBinary(t *testing.T) {
+	m := pgtype.NewMap()
+
+	// Results are requested in the text format. Only parameters prefer the binary format.
+	require.EqualValues(t, pgtype.TextFormatCode, m.FormatCodeForOID(pgtype.JSONBOID))
+	require.EqualValues(t, pgtype.BinaryFormatCode, m.ParamFormatCodeForOID(pgtype.JSONBOID))
+	require.EqualValues(t, pgtype.TextFormatCode, m.FormatCodeForOID(pgtype.JSONOID))
+	require.EqualValues(t, pgtype.TextFormatCode, m.ParamFormatCodeForOID(pgtype.JSONOID))
+
+	buf, err := m.Encode(pgtype.JSONBOID, pgtype.BinaryFormatCode, map[string]any{"foo": "bar"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{1}, `{"foo":"bar"}`...), buf)
+
+	buf, err = m.Encode(pgtype.JSONBOID, pgtype.BinaryFormatCode, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, buf)
+
+	var dst map[string]any
+	err = m.Scan(pgtype.JSONBOID, pgtype.BinaryFormatCode, append([]byte{1}, `{"foo":"bar"}`...), &dst)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"foo": "bar"}, dst)
+}
+
+func TestJSONBCodecScanFormats(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, tt := range []struct {
+		format int16
+		src    []byte
+	}{
+		{pgtype.TextFormatCode, []byte(`{"foo":"bar"}`)},
+		{pgtype.BinaryFormatCode, append([]byte{1}, `{"foo":"bar"}`...)},
+	} {
+		var s string
+		err := m.Scan(pgtype.JSONBOID, tt.format, tt.src, &s)
+		require.NoError(t, err)
+		require.Equal(t, `{"foo":"bar"}`, s)
+
+		var b []byte
+		err = m.Scan(pgtype.JSONBOID, tt.format, tt.src, &b)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"foo":"bar"}`), b)
+
+		var rm json.RawMessage
+		err = m.Scan(pgtype.JSONBOID, tt.format, tt.src, &rm)
+		require.NoError(t, err)
+		require.Equal(t, json.RawMessage(`{"foo":"bar"}`), rm)
+	}
+}
+
+func TestJSONBScanResultFormats(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		for _, format := range []int16{pgx.TextFormatCode, pgx.BinaryFormatCode} {
+			var s string
+			var b []byte
+			var rm json.RawMessage
+			err := conn.QueryRow(ctx, `select '{"foo":"bar"}'::jsonb, '{"foo":"bar"}'::jsonb, '{"foo":"bar"}'::jsonb`,
+				pgx.QueryResultFormats{format, format, format},
+			).Scan(&s, &b, &rm)
+			require.NoError(t, err)
+			require.Equal(t, `{"foo": "bar"}`, s)
+			require.Equal(t, []byte(`{"foo": "bar"}`), b)
+			require.Equal(t, json.RawMessage(`{"foo": "bar"}`), rm)
+		}
+	})
+}
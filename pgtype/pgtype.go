@@ -20,6 +20,7 @@ const (
 	Int8OID                = 20
 	Int2OID                = 21
 	Int4OID                = 23
+	RegprocOID             = 24
 	TextOID                = 25
 	OIDOID                 = 26
 	TIDOID                 = 27
@@ -27,6 +28,7 @@ const (
 	CIDOID                 = 29
 	JSONOID                = 114
 	JSONArrayOID           = 199
+	XID8ArrayOID           = 271
 	PointOID               = 600
 	LsegOID                = 601
 	PathOID                = 602
@@ -48,6 +50,7 @@ const (
 	NameArrayOID           = 1003
 	Int2ArrayOID           = 1005
 	Int4ArrayOID           = 1007
+	RegprocArrayOID        = 1008
 	TextArrayOID           = 1009
 	TIDArrayOID            = 1010
 	ByteaArrayOID          = 1001
@@ -86,10 +89,28 @@ const (
 	VarbitOID              = 1562
 	VarbitArrayOID         = 1563
 	NumericOID             = 1700
+	RegprocedureOID        = 2202
+	RegoperOID             = 2203
+	RegoperatorOID         = 2204
+	RegclassOID            = 2205
+	RegtypeOID             = 2206
+	RegprocedureArrayOID   = 2207
+	RegoperArrayOID        = 2208
+	RegoperatorArrayOID    = 2209
+	RegclassArrayOID       = 2210
+	RegtypeArrayOID        = 2211
 	RecordOID              = 2249
 	RecordArrayOID         = 2287
 	UUIDOID                = 2950
 	UUIDArrayOID           = 2951
+	TSVectorOID            = 3614
+	TSQueryOID             = 3615
+	TSVectorArrayOID       = 3643
+	TSQueryArrayOID        = 3645
+	RegconfigOID           = 3734
+	RegconfigArrayOID      = 3735
+	RegdictionaryOID       = 3769
+	RegdictionaryArrayOID  = 3770
 	JSONBOID               = 3802
 	JSONBArrayOID          = 3807
 	DaterangeOID           = 3912
@@ -104,12 +125,19 @@ const (
 	TstzrangeArrayOID      = 3911
 	Int8rangeOID           = 3926
 	Int8rangeArrayOID      = 3927
+	RegnamespaceOID        = 4089
+	RegnamespaceArrayOID   = 4090
+	RegroleOID             = 4096
+	RegroleArrayOID        = 4097
+	RegcollationOID        = 4191
+	RegcollationArrayOID   = 4192
 	Int4multirangeOID      = 4451
 	NummultirangeOID       = 4532
 	TsmultirangeOID        = 4533
 	TstzmultirangeOID      = 4534
 	DatemultirangeOID      = 4535
 	Int8multirangeOID      = 4536
+	XID8OID                = 5069
 	Int4multirangeArrayOID = 6150
 	NummultirangeArrayOID  = 6151
 	TsmultirangeArrayOID   = 6152
@@ -168,6 +196,13 @@ type Codec interface {
 	DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error)
 }
 
+// ParamFormatPreferrer may be implemented by a Codec that prefers a different format for query parameters than the
+// format returned by PreferredFormat, which is used for results.
+type ParamFormatPreferrer interface {
+	// PreferredParamFormat returns the preferred format for query parameters.
+	PreferredParamFormat() int16
+}
+
 type nullAssignmentError struct {
 	dst any
 }
@@ -185,10 +220,11 @@ type Type struct {
 // Map is the mapping between PostgreSQL server types and Go type handling logic. It can encode values for
 // transmission to a PostgreSQL server and scan received values.
 type Map struct {
-	oidToType         map[uint32]*Type
-	nameToType        map[string]*Type
-	reflectTypeToName map[reflect.Type]string
-	oidToFormatCode   map[uint32]int16
+	oidToType            map[uint32]*Type
+	nameToType           map[string]*Type
+	reflectTypeToName    map[reflect.Type]string
+	oidToFormatCode      map[uint32]int16
+	oidToParamFormatCode map[uint32]int16
 
 	reflectTypeToType map[reflect.Type]*Type
 
@@ -206,14 +242,17 @@ type Map struct {
 	// to be built up. There are default functions placed in this slice by NewMap(). In most cases these functions
 	// should run last. i.e. Additional functions should typically be prepended not appended.
 	TryWrapScanPlanFuncs []TryWrapScanPlanFunc
+
+	fallbackDecoder FallbackDecoderFunc
 }
 
 func NewMap() *Map {
 	m := &Map{
-		oidToType:         make(map[uint32]*Type),
-		nameToType:        make(map[string]*Type),
-		reflectTypeToName: make(map[reflect.Type]string),
-		oidToFormatCode:   make(map[uint32]int16),
+		oidToType:            make(map[uint32]*Type),
+		nameToType:           make(map[string]*Type),
+		reflectTypeToName:    make(map[reflect.Type]string),
+		oidToFormatCode:      make(map[uint32]int16),
+		oidToParamFormatCode: make(map[uint32]int16),
 
 		memoizedScanPlans:   make(map[uint32]map[reflect.Type][2]ScanPlan),
 		memoizedEncodePlans: make(map[uint32]map[reflect.Type][2]EncodePlan),
@@ -270,16 +309,30 @@ func NewMap() *Map {
 	m.RegisterType(&Type{Name: "point", OID: PointOID, Codec: PointCodec{}})
 	m.RegisterType(&Type{Name: "polygon", OID: PolygonOID, Codec: PolygonCodec{}})
 	m.RegisterType(&Type{Name: "record", OID: RecordOID, Codec: RecordCodec{}})
+	m.RegisterType(&Type{Name: "regclass", OID: RegclassOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regcollation", OID: RegcollationOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regconfig", OID: RegconfigOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regdictionary", OID: RegdictionaryOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regnamespace", OID: RegnamespaceOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regoper", OID: RegoperOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regoperator", OID: RegoperatorOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regproc", OID: RegprocOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regprocedure", OID: RegprocedureOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regrole", OID: RegroleOID, Codec: RegCodec{}})
+	m.RegisterType(&Type{Name: "regtype", OID: RegtypeOID, Codec: RegCodec{}})
 	m.RegisterType(&Type{Name: "text", OID: TextOID, Codec: TextCodec{}})
 	m.RegisterType(&Type{Name: "tid", OID: TIDOID, Codec: TIDCodec{}})
 	m.RegisterType(&Type{Name: "time", OID: TimeOID, Codec: TimeCodec{}})
 	m.RegisterType(&Type{Name: "timestamp", OID: TimestampOID, Codec: TimestampCodec{}})
 	m.RegisterType(&Type{Name: "timestamptz", OID: TimestamptzOID, Codec: TimestamptzCodec{}})
+	m.RegisterType(&Type{Name: "tsquery", OID: TSQueryOID, Codec: TSQueryCodec{}})
+	m.RegisterType(&Type{Name: "tsvector", OID: TSVectorOID, Codec: TSVectorCodec{}})
 	m.RegisterType(&Type{Name: "unknown", OID: UnknownOID, Codec: TextCodec{}})
 	m.RegisterType(&Type{Name: "uuid", OID: UUIDOID, Codec: UUIDCodec{}})
 	m.RegisterType(&Type{Name: "varbit", OID: VarbitOID, Codec: BitsCodec{}})
 	m.RegisterType(&Type{Name: "varchar", OID: VarcharOID, Codec: TextCodec{}})
 	m.RegisterType(&Type{Name: "xid", OID: XIDOID, Codec: Uint32Codec{}})
+	m.RegisterType(&Type{Name: "xid8", OID: XID8OID, Codec: Uint64Codec{}})
 
 	// Range types
 	m.RegisterType(&Type{Name: "daterange", OID: DaterangeOID, Codec: &RangeCodec{ElementType: m.oidToType[DateOID]}})
@@ -332,17 +385,31 @@ func NewMap() *Map {
 	m.RegisterType(&Type{Name: "_point", OID: PointArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[PointOID]}})
 	m.RegisterType(&Type{Name: "_polygon", OID: PolygonArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[PolygonOID]}})
 	m.RegisterType(&Type{Name: "_record", OID: RecordArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RecordOID]}})
+	m.RegisterType(&Type{Name: "_regclass", OID: RegclassArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegclassOID]}})
+	m.RegisterType(&Type{Name: "_regcollation", OID: RegcollationArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegcollationOID]}})
+	m.RegisterType(&Type{Name: "_regconfig", OID: RegconfigArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegconfigOID]}})
+	m.RegisterType(&Type{Name: "_regdictionary", OID: RegdictionaryArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegdictionaryOID]}})
+	m.RegisterType(&Type{Name: "_regnamespace", OID: RegnamespaceArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegnamespaceOID]}})
+	m.RegisterType(&Type{Name: "_regoper", OID: RegoperArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegoperOID]}})
+	m.RegisterType(&Type{Name: "_regoperator", OID: RegoperatorArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegoperatorOID]}})
+	m.RegisterType(&Type{Name: "_regproc", OID: RegprocArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegprocOID]}})
+	m.RegisterType(&Type{Name: "_regprocedure", OID: RegprocedureArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegprocedureOID]}})
+	m.RegisterType(&Type{Name: "_regrole", OID: RegroleArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegroleOID]}})
+	m.RegisterType(&Type{Name: "_regtype", OID: RegtypeArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[RegtypeOID]}})
 	m.RegisterType(&Type{Name: "_text", OID: TextArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TextOID]}})
 	m.RegisterType(&Type{Name: "_tid", OID: TIDArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TIDOID]}})
 	m.RegisterType(&Type{Name: "_time", OID: TimeArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TimeOID]}})
 	m.RegisterType(&Type{Name: "_timestamp", OID: TimestampArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TimestampOID]}})
 	m.RegisterType(&Type{Name: "_timestamptz", OID: TimestamptzArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TimestamptzOID]}})
+	m.RegisterType(&Type{Name: "_tsquery", OID: TSQueryArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TSQueryOID]}})
 	m.RegisterType(&Type{Name: "_tsrange", OID: TsrangeArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TsrangeOID]}})
 	m.RegisterType(&Type{Name: "_tstzrange", OID: TstzrangeArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TstzrangeOID]}})
+	m.RegisterType(&Type{Name: "_tsvector", OID: TSVectorArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[TSVectorOID]}})
 	m.RegisterType(&Type{Name: "_uuid", OID: UUIDArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[UUIDOID]}})
 	m.RegisterType(&Type{Name: "_varbit", OID: VarbitArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[VarbitOID]}})
 	m.RegisterType(&Type{Name: "_varchar", OID: VarcharArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[VarcharOID]}})
 	m.RegisterType(&Type{Name: "_xid", OID: XIDArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[XIDOID]}})
+	m.RegisterType(&Type{Name: "_xid8", OID: XID8ArrayOID, Codec: &ArrayCodec{ElementType: m.oidToType[XID8OID]}})
 
 	// Integer types that directly map to a PostgreSQL type
 	registerDefaultPgTypeVariants[int16](m, "int2")
@@ -405,6 +472,8 @@ func NewMap() *Map {
 	registerDefaultPgTypeVariants[Time](m, "time")
 	registerDefaultPgTypeVariants[Timestamp](m, "timestamp")
 	registerDefaultPgTypeVariants[Timestamptz](m, "timestamptz")
+	registerDefaultPgTypeVariants[TSQuery](m, "tsquery")
+	registerDefaultPgTypeVariants[TSVector](m, "tsvector")
 	registerDefaultPgTypeVariants[Range[Timestamp]](m, "tsrange")
 	registerDefaultPgTypeVariants[Multirange[Range[Timestamp]]](m, "tsmultirange")
 	registerDefaultPgTypeVariants[Range[Timestamptz]](m, "tstzrange")
@@ -418,6 +487,11 @@ func (m *Map) RegisterType(t *Type) {
 	m.oidToType[t.OID] = t
 	m.nameToType[t.Name] = t
 	m.oidToFormatCode[t.OID] = t.Codec.PreferredFormat()
+	if pfp, ok := t.Codec.(ParamFormatPreferrer); ok {
+		m.oidToParamFormatCode[t.OID] = pfp.PreferredParamFormat()
+	} else {
+		delete(m.oidToParamFormatCode, t.OID)
+	}
 
 	// Invalidated by type registration
 	m.reflectTypeToType = nil
@@ -429,6 +503,22 @@ func (m *Map) RegisterType(t *Type) {
 	}
 }
 
+// FallbackDecoderFunc decodes src of a type with an unregistered oid into target. src is nil for a NULL value.
+type FallbackDecoderFunc func(oid uint32, formatCode int16, src []byte, target any) error
+
+// RegisterFallbackDecoder registers fn to be used to scan values whose oid is not registered when no other way to scan
+// into the target is found. This allows handling types such as extension types or domains generically without
+// registering each type. For example, fn could store the raw text of any unknown type in an application defined type.
+// Only one fallback decoder can be registered. A nil fn removes the fallback decoder.
+func (m *Map) RegisterFallbackDecoder(fn FallbackDecoderFunc) {
+	m.fallbackDecoder = fn
+
+	// Invalidated by fallback decoder registration
+	for k := range m.memoizedScanPlans {
+		delete(m.memoizedScanPlans, k)
+	}
+}
+
 // RegisterDefaultPgType registers a mapping of a Go type to a PostgreSQL type name. Typically the data type to be
 // encoded or decoded is determined by the PostgreSQL OID. But if the OID of a value to be encoded or decoded is
 // unknown, this additional mapping will be used by TypeForValue to determine a suitable data type.
@@ -486,6 +576,16 @@ func (m *Map) FormatCodeForOID(oid uint32) int16 {
 	return TextFormatCode
 }
 
+// ParamFormatCodeForOID returns the preferred format code for a query parameter of type oid. It is the same as
+// FormatCodeForOID unless the Codec of the type implements ParamFormatPreferrer.
+func (m *Map) ParamFormatCodeForOID(oid uint32) int16 {
+	fc, ok := m.oidToParamFormatCode[oid]
+	if ok {
+		return fc
+	}
+	return m.FormatCodeForOID(oid)
+}
+
 // EncodePlan is a precompiled plan to encode a particular type into a particular OID and format.
 type EncodePlan interface {
 	// Encode appends the encoded bytes of value to buf. If value is the SQL value NULL then append nothing and return
@@ -561,6 +661,16 @@ func (scanPlanAnyTextToBytes) Scan(src []byte, dst any) error {
 	return nil
 }
 
+type scanPlanFallbackDecoder struct {
+	fn         FallbackDecoderFunc
+	oid        uint32
+	formatCode int16
+}
+
+func (plan *scanPlanFallbackDecoder) Scan(src []byte, dst any) error {
+	return plan.fn(plan.oid, plan.formatCode, src, dst)
+}
+
 type scanPlanFail struct {
 	m          *Map
 	oid        uint32
@@ -636,7 +746,9 @@ func TryPointerPointerScanPlan(target any) (plan WrappedScanPlanNextSetter, next
 		elemValue := dstValue.Elem()
 		if elemValue.Kind() == reflect.Ptr {
 			plan = &pointerPointerScanPlan{dstType: dstValue.Type()}
-			return plan, reflect.Zero(elemValue.Type()).Interface(), true
+			// Use a non-nil pointer as the next target so that deeper levels of pointers (e.g. ***int32) can also be
+			// unwrapped.
+			return plan, reflect.New(elemValue.Type().Elem()).Interface(), true
 		}
 	}
 
@@ -1186,6 +1298,11 @@ func (m *Map) PlanScan(oid uint32, formatCode int16, target any) ScanPlan {
 	plan := typeMemo[formatCode]
 	if plan == nil {
 		plan = m.planScan(oid, formatCode, target)
+		if _, failed := plan.(*scanPlanFail); failed && m.fallbackDecoder != nil {
+			if _, ok := m.TypeForOID(oid); !ok {
+				plan = &scanPlanFallbackDecoder{fn: m.fallbackDecoder, oid: oid, formatCode: formatCode}
+			}
+		}
 		typeMemo[formatCode] = plan
 		oidMemo[targetReflectType] = typeMemo
 	}
@@ -240,6 +240,42 @@ func TestMapScanUnregisteredOIDIntoRenamedStringSQLScanner(t *testing.T) {
 	assert.Equal(t, "scanned", string(s))
 }
 
+type unknownTypeValue struct {
+	oid        uint32
+	formatCode int16
+	raw        string
+}
+
+func TestMapScanUnregisteredOIDWithFallbackDecoder(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var v unknownTypeValue
+	err := m.Scan(unregisteredOID, pgx.BinaryFormatCode, []byte{1, 2, 3}, &v)
+	require.Error(t, err)
+
+	m.RegisterFallbackDecoder(func(oid uint32, formatCode int16, src []byte, target any) error {
+		v, ok := target.(*unknownTypeValue)
+		if !ok {
+			return fmt.Errorf("cannot scan into %T", target)
+		}
+		*v = unknownTypeValue{oid: oid, formatCode: formatCode, raw: string(src)}
+		return nil
+	})
+
+	err = m.Scan(unregisteredOID, pgx.BinaryFormatCode, []byte("abc"), &v)
+	require.NoError(t, err)
+	require.Equal(t, unknownTypeValue{oid: unregisteredOID, formatCode: pgx.BinaryFormatCode, raw: "abc"}, v)
+
+	// Registered types and targets that can already be scanned do not use the fallback decoder.
+	var s string
+	err = m.Scan(unregisteredOID, pgx.TextFormatCode, []byte("abc"), &s)
+	require.NoError(t, err)
+	require.Equal(t, "abc", s)
+
+	err = m.Scan(pgtype.Int4OID, pgx.BinaryFormatCode, []byte{0, 0, 0, 1}, &v)
+	require.Error(t, err)
+}
+
 type pgCustomInt int64
 
 func (ci *pgCustomInt) Scan(src interface{}) error {
@@ -302,6 +338,30 @@ func TestMapScanPtrToPtrToSlice(t *testing.T) {
 	require.Equal(t, []string{"foo", "bar"}, *v)
 }
 
+func TestMapScanPtrToPtrNullable(t *testing.T) {
+	m := pgtype.NewMap()
+
+	n := int32(7)
+	v := &n
+	err := m.Scan(pgtype.Int4OID, pgtype.BinaryFormatCode, nil, &v)
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	err = m.Scan(pgtype.Int4OID, pgtype.BinaryFormatCode, []byte{0, 0, 0, 42}, &v)
+	require.NoError(t, err)
+	require.NotNil(t, v)
+	require.EqualValues(t, 42, *v)
+
+	var vv **int32
+	err = m.Scan(pgtype.Int4OID, pgtype.TextFormatCode, []byte("42"), &vv)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, **vv)
+
+	err = m.Scan(pgtype.Int4OID, pgtype.TextFormatCode, nil, &vv)
+	require.NoError(t, err)
+	require.Nil(t, vv)
+}
+
 type databaseValuerString string
 
 func (s databaseValuerString) Value() (driver.Value, error) {
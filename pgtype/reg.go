@@ -0,0 +1,177 @@
+package pgtype
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// RegCodec is the codec for the OID alias types such as regclass, regtype, and regproc. In the binary format these
+// types are the numeric OID of the referenced object. In the text format they are the name of the object (or the
+// number if no object with that OID exists). RegCodec prefers the text format so names are returned by default.
+//
+// The value is scanned according to the format the server sent: a string destination receives the name in the text
+// format and the decimal OID in the binary format. A uint32 destination receives the OID in the binary format, but in
+// the text format it can only be scanned when the server sent a number. Cast the column to oid (e.g.
+// 'pg_class'::regclass::oid) to read the OID regardless of format.
+//
+// Parameters may be encoded from a uint32 OID or a string name. PostgreSQL resolves a name to the OID of the object.
+type RegCodec struct{}
+
+func (RegCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (RegCodec) PreferredFormat() int16 {
+	return TextFormatCode
+}
+
+func (RegCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	// string and TextValuer values in the text format are handled by Map.PlanEncode.
+	return Uint32Codec{}.PlanEncode(m, oid, format, value)
+}
+
+func (RegCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	switch format {
+	case BinaryFormatCode:
+		switch target.(type) {
+		case *string:
+			return scanPlanBinaryRegToString{}
+		case TextScanner:
+			return scanPlanBinaryRegToTextScanner{}
+		}
+	case TextFormatCode:
+		switch target.(type) {
+		case *uint32:
+			return scanPlanTextRegToUint32{}
+		case Uint32Scanner:
+			return scanPlanTextRegToUint32Scanner{}
+		}
+	}
+
+	return Uint32Codec{}.PlanScan(m, oid, format, target)
+}
+
+func (c RegCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	if format == BinaryFormatCode {
+		return Uint32Codec{}.DecodeDatabaseSQLValue(m, oid, format, src)
+	}
+
+	return string(src), nil
+}
+
+func (c RegCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	if format == BinaryFormatCode {
+		return Uint32Codec{}.DecodeValue(m, oid, format, src)
+	}
+
+	return string(src), nil
+}
+
+func decodeBinaryReg(src []byte) (string, error) {
+	if len(src) != 4 {
+		return "", fmt.Errorf("invalid length for oid: %v", len(src))
+	}
+
+	return strconv.FormatUint(uint64(binary.BigEndian.Uint32(src)), 10), nil
+}
+
+func parseTextReg(src []byte) (uint32, error) {
+	n, err := strconv.ParseUint(string(src), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("cannot scan object name %q into uint32: cast to oid to read the OID", src)
+	}
+
+	return uint32(n), nil
+}
+
+type scanPlanBinaryRegToString struct{}
+
+func (scanPlanBinaryRegToString) Scan(src []byte, dst any) error {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	p, ok := (dst).(*string)
+	if !ok {
+		return ErrScanTargetTypeChanged
+	}
+
+	s, err := decodeBinaryReg(src)
+	if err != nil {
+		return err
+	}
+
+	*p = s
+	return nil
+}
+
+type scanPlanBinaryRegToTextScanner struct{}
+
+func (scanPlanBinaryRegToTextScanner) Scan(src []byte, dst any) error {
+	scanner, ok := (dst).(TextScanner)
+	if !ok {
+		return ErrScanTargetTypeChanged
+	}
+
+	if src == nil {
+		return scanner.ScanText(Text{})
+	}
+
+	s, err := decodeBinaryReg(src)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanText(Text{String: s, Valid: true})
+}
+
+type scanPlanTextRegToUint32 struct{}
+
+func (scanPlanTextRegToUint32) Scan(src []byte, dst any) error {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	p, ok := (dst).(*uint32)
+	if !ok {
+		return ErrScanTargetTypeChanged
+	}
+
+	n, err := parseTextReg(src)
+	if err != nil {
+		return err
+	}
+
+	*p = n
+	return nil
+}
+
+type scanPlanTextRegToUint32Scanner struct{}
+
+func (scanPlanTextRegToUint32Scanner) Scan(src []byte, dst any) error {
+	s, ok := (dst).(Uint32Scanner)
+	if !ok {
+		return ErrScanTargetTypeChanged
+	}
+
+	if src == nil {
+		return s.ScanUint32(Uint32{})
+	}
+
+	n, err := parseTextReg(src)
+	if err != nil {
+		return err
+	}
+
+	return s.ScanUint32(Uint32{Uint32: n, Valid: true})
+}
@@ -0,0 +1,71 @@
+package pgtype_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegCodec(t *testing.T) {
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, "regtype", []pgxtest.ValueRoundTripTest{
+		{"text", new(string), isExpectedEq("text")},
+		{uint32(pgtype.TextOID), new(string), isExpectedEq("text")},
+		{pgtype.Uint32{Uint32: pgtype.Int4OID, Valid: true}, new(string), isExpectedEq("integer")},
+		{"123456789", new(uint32), isExpectedEq(uint32(123456789))},
+		{pgtype.Uint32{}, new(pgtype.Uint32), isExpectedEq(pgtype.Uint32{})},
+		{nil, new(*string), isExpectedEq((*string)(nil))},
+	})
+}
+
+func TestRegCodecScan(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var s string
+	err := m.Scan(pgtype.RegclassOID, pgtype.TextFormatCode, []byte("pg_class"), &s)
+	require.NoError(t, err)
+	require.Equal(t, "pg_class", s)
+
+	err = m.Scan(pgtype.RegclassOID, pgtype.BinaryFormatCode, []byte{0, 0, 0x04, 0xeb}, &s)
+	require.NoError(t, err)
+	require.Equal(t, "1259", s)
+
+	var n uint32
+	err = m.Scan(pgtype.RegclassOID, pgtype.BinaryFormatCode, []byte{0, 0, 0x04, 0xeb}, &n)
+	require.NoError(t, err)
+	require.EqualValues(t, 1259, n)
+
+	err = m.Scan(pgtype.RegclassOID, pgtype.TextFormatCode, []byte("1259"), &n)
+	require.NoError(t, err)
+	require.EqualValues(t, 1259, n)
+
+	err = m.Scan(pgtype.RegclassOID, pgtype.TextFormatCode, []byte("pg_class"), &n)
+	require.ErrorContains(t, err, "cast to oid")
+
+	var v any
+	err = m.Scan(pgtype.RegclassOID, pgtype.TextFormatCode, []byte("pg_class"), &v)
+	require.NoError(t, err)
+	require.Equal(t, "pg_class", v)
+
+	err = m.Scan(pgtype.RegclassOID, pgtype.BinaryFormatCode, []byte{0, 0, 0x04, 0xeb}, &v)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1259), v)
+}
+
+func TestRegCodecEncode(t *testing.T) {
+	m := pgtype.NewMap()
+
+	buf, err := m.Encode(pgtype.RegclassOID, pgtype.TextFormatCode, "pg_class", nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("pg_class"), buf)
+
+	buf, err = m.Encode(pgtype.RegclassOID, pgtype.BinaryFormatCode, uint32(1259), nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0x04, 0xeb}, buf)
+
+	buf, err = m.Encode(pgtype.RegclassOID, pgtype.TextFormatCode, pgtype.Uint32{Uint32: 1259, Valid: true}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("1259"), buf)
+}
@@ -63,6 +63,26 @@ func TestTimestamptzDecodeTextInvalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestTimestamptzCodecScanLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	c := pgtype.TimestamptzCodec{ScanLocation: loc}
+	expected := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	var tstz pgtype.Timestamptz
+	plan := c.PlanScan(nil, pgtype.TimestamptzOID, pgtype.TextFormatCode, &tstz)
+	err = plan.Scan([]byte("2022-07-01 12:00:00+00"), &tstz)
+	require.NoError(t, err)
+	require.Truef(t, expected.Equal(tstz.Time), "expected %v got %v", expected, tstz.Time)
+	require.Equal(t, loc, tstz.Time.Location())
+
+	plan = c.PlanScan(nil, pgtype.TimestamptzOID, pgtype.BinaryFormatCode, &tstz)
+	err = plan.Scan([]byte{0, 2, 140, 214, 14, 101, 96, 0}, &tstz)
+	require.NoError(t, err)
+	require.Equal(t, loc, tstz.Time.Location())
+}
+
 func TestTimestamptzMarshalJSON(t *testing.T) {
 	successfulTests := []struct {
 		source pgtype.Timestamptz
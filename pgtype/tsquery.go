@@ -0,0 +1,643 @@
+package pgtype
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+type TSQueryScanner interface {
+	ScanTSQuery(v TSQuery) error
+}
+
+type TSQueryValuer interface {
+	TSQueryValue() (TSQuery, error)
+}
+
+// TSQueryOperator is the operator of a TSQueryNode.
+type TSQueryOperator uint8
+
+const (
+	TSQueryOperand TSQueryOperator = 0 // The node is a lexeme.
+	TSQueryNot     TSQueryOperator = 1 // !Right
+	TSQueryAnd     TSQueryOperator = 2 // Left & Right
+	TSQueryOr      TSQueryOperator = 3 // Left | Right
+	TSQueryPhrase  TSQueryOperator = 4 // Left <Distance> Right
+)
+
+// TSQueryWeights is a set of weights a tsquery lexeme matches. The zero value matches any weight.
+type TSQueryWeights uint8
+
+const (
+	TSQueryWeightD TSQueryWeights = 1 << iota
+	TSQueryWeightC
+	TSQueryWeightB
+	TSQueryWeightA
+)
+
+// TSQueryNode is a node of a tsquery expression tree. Operator determines which of the other fields are used.
+type TSQueryNode struct {
+	Operator TSQueryOperator
+
+	// Lexeme, Weights, and Prefix are used by TSQueryOperand.
+	Lexeme  string
+	Weights TSQueryWeights
+	Prefix  bool
+
+	// Distance is used by TSQueryPhrase. The <-> operator has a distance of 1.
+	Distance uint16
+
+	// Left is used by the binary operators. Right is used by the binary operators and TSQueryNot.
+	Left  *TSQueryNode
+	Right *TSQueryNode
+}
+
+// TSQuery represents the PostgreSQL tsquery type. Root is nil for an empty query.
+type TSQuery struct {
+	Root  *TSQueryNode
+	Valid bool
+}
+
+func (q *TSQuery) ScanTSQuery(v TSQuery) error {
+	*q = v
+	return nil
+}
+
+func (q TSQuery) TSQueryValue() (TSQuery, error) {
+	return q, nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (q *TSQuery) Scan(src any) error {
+	if src == nil {
+		*q = TSQuery{}
+		return nil
+	}
+
+	switch src := src.(type) {
+	case string:
+		return scanPlanTextAnyToTSQueryScanner{}.Scan([]byte(src), q)
+	}
+
+	return fmt.Errorf("cannot scan %T", src)
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (q TSQuery) Value() (driver.Value, error) {
+	if !q.Valid {
+		return nil, nil
+	}
+
+	buf, err := TSQueryCodec{}.PlanEncode(nil, 0, TextFormatCode, q).Encode(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(buf), err
+}
+
+// tsQueryOperatorPriority returns the binding strength of op. It matches the priorities PostgreSQL uses to parse and
+// format tsquery.
+func tsQueryOperatorPriority(op TSQueryOperator) int {
+	switch op {
+	case TSQueryNot:
+		return 4
+	case TSQueryPhrase:
+		return 3
+	case TSQueryAnd:
+		return 2
+	case TSQueryOr:
+		return 1
+	default:
+		return 5
+	}
+}
+
+type TSQueryCodec struct{}
+
+func (TSQueryCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (TSQueryCodec) PreferredFormat() int16 {
+	return BinaryFormatCode
+}
+
+func (TSQueryCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	if _, ok := value.(TSQueryValuer); !ok {
+		return nil
+	}
+
+	switch format {
+	case BinaryFormatCode:
+		return encodePlanTSQueryCodecBinary{}
+	case TextFormatCode:
+		return encodePlanTSQueryCodecText{}
+	}
+
+	return nil
+}
+
+type encodePlanTSQueryCodecBinary struct{}
+
+func (encodePlanTSQueryCodecBinary) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	tsq, err := value.(TSQueryValuer).TSQueryValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !tsq.Valid {
+		return nil, nil
+	}
+
+	if tsq.Root == nil {
+		return pgio.AppendInt32(buf, 0), nil
+	}
+
+	sp := len(buf)
+	buf = pgio.AppendInt32(buf, -1)
+
+	// Nodes are sent in prefix order with the right operand before the left operand.
+	var itemCount int32
+	var appendNode func(node *TSQueryNode) error
+	appendNode = func(node *TSQueryNode) error {
+		if node == nil {
+			return errors.New("tsquery operator is missing an operand")
+		}
+		itemCount++
+
+		switch node.Operator {
+		case TSQueryOperand:
+			if node.Lexeme == "" {
+				return errors.New("tsquery lexeme must not be empty")
+			}
+			if strings.IndexByte(node.Lexeme, 0) != -1 {
+				return errors.New("tsquery lexeme must not contain a NUL byte")
+			}
+			buf = append(buf, 1, byte(node.Weights), 0)
+			if node.Prefix {
+				buf[len(buf)-1] = 1
+			}
+			buf = append(buf, node.Lexeme...)
+			buf = append(buf, 0)
+			return nil
+		case TSQueryNot:
+			buf = append(buf, 2, byte(node.Operator))
+			return appendNode(node.Right)
+		case TSQueryAnd, TSQueryOr, TSQueryPhrase:
+			buf = append(buf, 2, byte(node.Operator))
+			if node.Operator == TSQueryPhrase {
+				buf = pgio.AppendUint16(buf, node.Distance)
+			}
+			if err := appendNode(node.Right); err != nil {
+				return err
+			}
+			return appendNode(node.Left)
+		default:
+			return fmt.Errorf("invalid tsquery operator: %d", node.Operator)
+		}
+	}
+
+	err = appendNode(tsq.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	pgio.SetInt32(buf[sp:], itemCount)
+
+	return buf, nil
+}
+
+type encodePlanTSQueryCodecText struct{}
+
+func (encodePlanTSQueryCodecText) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	tsq, err := value.(TSQueryValuer).TSQueryValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !tsq.Valid {
+		return nil, nil
+	}
+
+	if tsq.Root == nil {
+		return buf, nil
+	}
+
+	return appendTSQueryNodeText(buf, tsq.Root, 0, false)
+}
+
+// appendTSQueryNodeText appends node in the text format. Parentheses are added in the same places PostgreSQL adds
+// them.
+func appendTSQueryNodeText(buf []byte, node *TSQueryNode, parentPriority int, rightOfPhrase bool) ([]byte, error) {
+	if node == nil {
+		return nil, errors.New("tsquery operator is missing an operand")
+	}
+
+	var err error
+
+	switch node.Operator {
+	case TSQueryOperand:
+		if node.Lexeme == "" {
+			return nil, errors.New("tsquery lexeme must not be empty")
+		}
+		buf = appendTSQuoted(buf, node.Lexeme)
+		if node.Prefix || node.Weights != 0 {
+			buf = append(buf, ':')
+			if node.Prefix {
+				buf = append(buf, '*')
+			}
+			if node.Weights&TSQueryWeightA != 0 {
+				buf = append(buf, 'A')
+			}
+			if node.Weights&TSQueryWeightB != 0 {
+				buf = append(buf, 'B')
+			}
+			if node.Weights&TSQueryWeightC != 0 {
+				buf = append(buf, 'C')
+			}
+			if node.Weights&TSQueryWeightD != 0 {
+				buf = append(buf, 'D')
+			}
+		}
+		return buf, nil
+	case TSQueryNot:
+		priority := tsQueryOperatorPriority(node.Operator)
+		needParens := priority < parentPriority
+		if needParens {
+			buf = append(buf, "( "...)
+		}
+		buf = append(buf, '!')
+		buf, err = appendTSQueryNodeText(buf, node.Right, priority, false)
+		if err != nil {
+			return nil, err
+		}
+		if needParens {
+			buf = append(buf, " )"...)
+		}
+		return buf, nil
+	case TSQueryAnd, TSQueryOr, TSQueryPhrase:
+		priority := tsQueryOperatorPriority(node.Operator)
+		needParens := priority < parentPriority || (rightOfPhrase && node.Operator == TSQueryPhrase)
+		if needParens {
+			buf = append(buf, "( "...)
+		}
+		buf, err = appendTSQueryNodeText(buf, node.Left, priority, false)
+		if err != nil {
+			return nil, err
+		}
+		switch node.Operator {
+		case TSQueryAnd:
+			buf = append(buf, " & "...)
+		case TSQueryOr:
+			buf = append(buf, " | "...)
+		case TSQueryPhrase:
+			if node.Distance == 1 {
+				buf = append(buf, " <-> "...)
+			} else {
+				buf = append(buf, " <"...)
+				buf = strconv.AppendUint(buf, uint64(node.Distance), 10)
+				buf = append(buf, "> "...)
+			}
+		}
+		buf, err = appendTSQueryNodeText(buf, node.Right, priority, node.Operator == TSQueryPhrase)
+		if err != nil {
+			return nil, err
+		}
+		if needParens {
+			buf = append(buf, " )"...)
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("invalid tsquery operator: %d", node.Operator)
+	}
+}
+
+func (TSQueryCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+
+	switch format {
+	case BinaryFormatCode:
+		switch target.(type) {
+		case TSQueryScanner:
+			return scanPlanBinaryTSQueryToTSQueryScanner{}
+		case TextScanner:
+			return scanPlanBinaryTSQueryToTextScanner{}
+		}
+	case TextFormatCode:
+		switch target.(type) {
+		case TSQueryScanner:
+			return scanPlanTextAnyToTSQueryScanner{}
+		}
+	}
+
+	return nil
+}
+
+type scanPlanBinaryTSQueryToTSQueryScanner struct{}
+
+func (scanPlanBinaryTSQueryToTSQueryScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(TSQueryScanner)
+
+	if src == nil {
+		return scanner.ScanTSQuery(TSQuery{})
+	}
+
+	tsq, err := decodeBinaryTSQuery(src)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanTSQuery(tsq)
+}
+
+type scanPlanBinaryTSQueryToTextScanner struct{}
+
+func (scanPlanBinaryTSQueryToTextScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(TextScanner)
+
+	if src == nil {
+		return scanner.ScanText(Text{})
+	}
+
+	tsq, err := decodeBinaryTSQuery(src)
+	if err != nil {
+		return err
+	}
+
+	buf, err := encodePlanTSQueryCodecText{}.Encode(tsq, nil)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanText(Text{String: string(buf), Valid: true})
+}
+
+func decodeBinaryTSQuery(src []byte) (TSQuery, error) {
+	if len(src) < 4 {
+		return TSQuery{}, fmt.Errorf("tsquery too short: %d", len(src))
+	}
+	itemCount := int(int32(binary.BigEndian.Uint32(src)))
+	rp := 4
+
+	if itemCount == 0 {
+		return TSQuery{Valid: true}, nil
+	}
+
+	var readNode func() (*TSQueryNode, error)
+	readNode = func() (*TSQueryNode, error) {
+		if itemCount == 0 {
+			return nil, errors.New("invalid tsquery: missing operand")
+		}
+		itemCount--
+
+		if len(src[rp:]) < 2 {
+			return nil, errors.New("invalid tsquery: truncated item")
+		}
+		itemType := src[rp]
+		rp++
+
+		switch itemType {
+		case 1:
+			if len(src[rp:]) < 2 {
+				return nil, errors.New("invalid tsquery: truncated operand")
+			}
+			node := &TSQueryNode{Weights: TSQueryWeights(src[rp]), Prefix: src[rp+1] != 0}
+			rp += 2
+
+			end := rp
+			for end < len(src) && src[end] != 0 {
+				end++
+			}
+			if end == len(src) {
+				return nil, errors.New("invalid tsquery: unterminated lexeme")
+			}
+			node.Lexeme = string(src[rp:end])
+			rp = end + 1
+			return node, nil
+		case 2:
+			node := &TSQueryNode{Operator: TSQueryOperator(src[rp])}
+			rp++
+
+			var err error
+			switch node.Operator {
+			case TSQueryNot:
+				node.Right, err = readNode()
+				if err != nil {
+					return nil, err
+				}
+			case TSQueryAnd, TSQueryOr, TSQueryPhrase:
+				if node.Operator == TSQueryPhrase {
+					if len(src[rp:]) < 2 {
+						return nil, errors.New("invalid tsquery: truncated phrase distance")
+					}
+					node.Distance = binary.BigEndian.Uint16(src[rp:])
+					rp += 2
+				}
+				node.Right, err = readNode()
+				if err != nil {
+					return nil, err
+				}
+				node.Left, err = readNode()
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("invalid tsquery operator: %d", node.Operator)
+			}
+			return node, nil
+		default:
+			return nil, fmt.Errorf("invalid tsquery item type: %d", itemType)
+		}
+	}
+
+	root, err := readNode()
+	if err != nil {
+		return TSQuery{}, err
+	}
+
+	if itemCount != 0 || rp != len(src) {
+		return TSQuery{}, errors.New("invalid tsquery: extra data")
+	}
+
+	return TSQuery{Root: root, Valid: true}, nil
+}
+
+type scanPlanTextAnyToTSQueryScanner struct{}
+
+func (scanPlanTextAnyToTSQueryScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(TSQueryScanner)
+
+	if src == nil {
+		return scanner.ScanTSQuery(TSQuery{})
+	}
+
+	tsq, err := parseTSQuery(string(src))
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanTSQuery(tsq)
+}
+
+// parseTSQuery parses the text format of a tsquery, e.g. 'fat' & ( 'rat' | !'cat' ).
+func parseTSQuery(s string) (TSQuery, error) {
+	p := &tsParser{s: s}
+
+	p.skipSpace()
+	if p.eof() {
+		return TSQuery{Valid: true}, nil
+	}
+
+	root, err := p.parseTSQueryExpr(0)
+	if err != nil {
+		return TSQuery{}, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		return TSQuery{}, fmt.Errorf("unexpected %q in tsquery at position %d", p.s[p.pos], p.pos)
+	}
+
+	return TSQuery{Root: root, Valid: true}, nil
+}
+
+// parseTSQueryExpr parses an expression whose binary operators have at least minPriority. Binary operators are left
+// associative.
+func (p *tsParser) parseTSQueryExpr(minPriority int) (*TSQueryNode, error) {
+	left, err := p.parseTSQueryUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return left, nil
+		}
+
+		node := &TSQueryNode{}
+		var opLen int
+		switch p.s[p.pos] {
+		case '&':
+			node.Operator = TSQueryAnd
+			opLen = 1
+		case '|':
+			node.Operator = TSQueryOr
+			opLen = 1
+		case '<':
+			end := strings.IndexByte(p.s[p.pos:], '>')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated phrase operator at position %d", p.pos)
+			}
+			distance := p.s[p.pos+1 : p.pos+end]
+			node.Operator = TSQueryPhrase
+			if distance == "-" {
+				node.Distance = 1
+			} else {
+				n, err := strconv.ParseUint(distance, 10, 16)
+				if err != nil {
+					return nil, fmt.Errorf("invalid phrase distance: %v", err)
+				}
+				node.Distance = uint16(n)
+			}
+			opLen = end + 1
+		default:
+			return left, nil
+		}
+
+		priority := tsQueryOperatorPriority(node.Operator)
+		if priority < minPriority {
+			return left, nil
+		}
+		p.pos += opLen
+
+		right, err := p.parseTSQueryExpr(priority + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Left = left
+		node.Right = right
+		left = node
+	}
+}
+
+func (p *tsParser) parseTSQueryUnary() (*TSQueryNode, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, errors.New("unexpected end of tsquery")
+	}
+
+	switch p.s[p.pos] {
+	case '!':
+		p.pos++
+		operand, err := p.parseTSQueryUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &TSQueryNode{Operator: TSQueryNot, Right: operand}, nil
+	case '(':
+		p.pos++
+		node, err := p.parseTSQueryExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() || p.s[p.pos] != ')' {
+			return nil, fmt.Errorf("expected ) in tsquery at position %d", p.pos)
+		}
+		p.pos++
+		return node, nil
+	}
+
+	lexeme, err := p.readLexeme()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &TSQueryNode{Lexeme: lexeme}
+	if !p.eof() && p.s[p.pos] == ':' {
+		p.pos++
+	flagLoop:
+		for !p.eof() {
+			switch p.s[p.pos] {
+			case '*':
+				node.Prefix = true
+			case 'A', 'a':
+				node.Weights |= TSQueryWeightA
+			case 'B', 'b':
+				node.Weights |= TSQueryWeightB
+			case 'C', 'c':
+				node.Weights |= TSQueryWeightC
+			case 'D', 'd':
+				node.Weights |= TSQueryWeightD
+			default:
+				break flagLoop
+			}
+			p.pos++
+		}
+	}
+
+	return node, nil
+}
+
+func (c TSQueryCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	return codecDecodeToTextFormat(c, m, oid, format, src)
+}
+
+func (c TSQueryCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	var tsq TSQuery
+	err := codecScan(c, m, oid, format, src, &tsq)
+	if err != nil {
+		return nil, err
+	}
+	return tsq, nil
+}
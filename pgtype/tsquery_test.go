@@ -0,0 +1,131 @@
+package pgtype_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func isExpectedEqTSQuery(a any) func(any) bool {
+	return func(v any) bool {
+		return reflect.DeepEqual(a, v)
+	}
+}
+
+func tsqLexeme(s string) *pgtype.TSQueryNode {
+	return &pgtype.TSQueryNode{Lexeme: s}
+}
+
+func TestTSQueryCodec(t *testing.T) {
+	skipCockroachDB(t, "Server does not support type tsquery")
+
+	// 'fat':*AB & ( 'rat' | !'cat' ) <2> 'mouse'
+	complexQuery := pgtype.TSQuery{
+		Root: &pgtype.TSQueryNode{
+			Operator: pgtype.TSQueryAnd,
+			Left:     &pgtype.TSQueryNode{Lexeme: "fat", Weights: pgtype.TSQueryWeightA | pgtype.TSQueryWeightB, Prefix: true},
+			Right: &pgtype.TSQueryNode{
+				Operator: pgtype.TSQueryPhrase,
+				Distance: 2,
+				Left: &pgtype.TSQueryNode{
+					Operator: pgtype.TSQueryOr,
+					Left:     tsqLexeme("rat"),
+					Right:    &pgtype.TSQueryNode{Operator: pgtype.TSQueryNot, Right: tsqLexeme("cat")},
+				},
+				Right: tsqLexeme("mouse"),
+			},
+		},
+		Valid: true,
+	}
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "tsquery", []pgxtest.ValueRoundTripTest{
+		{
+			pgtype.TSQuery{Root: tsqLexeme("it's"), Valid: true},
+			new(pgtype.TSQuery),
+			isExpectedEqTSQuery(pgtype.TSQuery{Root: tsqLexeme("it's"), Valid: true}),
+		},
+		{complexQuery, new(pgtype.TSQuery), isExpectedEqTSQuery(complexQuery)},
+		{complexQuery, new(string), isExpectedEq(`'fat':*AB & ( 'rat' | !'cat' ) <2> 'mouse'`)},
+		{pgtype.TSQuery{Valid: true}, new(pgtype.TSQuery), isExpectedEqTSQuery(pgtype.TSQuery{Valid: true})},
+		{pgtype.TSQuery{}, new(pgtype.TSQuery), isExpectedEqTSQuery(pgtype.TSQuery{})},
+		{nil, new(pgtype.TSQuery), isExpectedEqTSQuery(pgtype.TSQuery{})},
+	})
+}
+
+func TestTSQueryText(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for i, tt := range []struct {
+		text     string
+		expected *pgtype.TSQueryNode
+		output   string
+	}{
+		{
+			text: `'a' & 'b' & 'c'`,
+			expected: &pgtype.TSQueryNode{
+				Operator: pgtype.TSQueryAnd,
+				Left:     &pgtype.TSQueryNode{Operator: pgtype.TSQueryAnd, Left: tsqLexeme("a"), Right: tsqLexeme("b")},
+				Right:    tsqLexeme("c"),
+			},
+			output: `'a' & 'b' & 'c'`,
+		},
+		{
+			text: `a | b & !c`,
+			expected: &pgtype.TSQueryNode{
+				Operator: pgtype.TSQueryOr,
+				Left:     tsqLexeme("a"),
+				Right: &pgtype.TSQueryNode{
+					Operator: pgtype.TSQueryAnd,
+					Left:     tsqLexeme("b"),
+					Right:    &pgtype.TSQueryNode{Operator: pgtype.TSQueryNot, Right: tsqLexeme("c")},
+				},
+			},
+			output: `'a' | 'b' & !'c'`,
+		},
+		{
+			text: `!( 'a' | 'b' ) <-> ( 'c' <-> 'd' )`,
+			expected: &pgtype.TSQueryNode{
+				Operator: pgtype.TSQueryPhrase,
+				Distance: 1,
+				Left: &pgtype.TSQueryNode{
+					Operator: pgtype.TSQueryNot,
+					Right:    &pgtype.TSQueryNode{Operator: pgtype.TSQueryOr, Left: tsqLexeme("a"), Right: tsqLexeme("b")},
+				},
+				Right: &pgtype.TSQueryNode{Operator: pgtype.TSQueryPhrase, Distance: 1, Left: tsqLexeme("c"), Right: tsqLexeme("d")},
+			},
+			output: `!( 'a' | 'b' ) <-> ( 'c' <-> 'd' )`,
+		},
+		{
+			text:     `'x''y\\z':d*`,
+			expected: &pgtype.TSQueryNode{Lexeme: `x'y\z`, Weights: pgtype.TSQueryWeightD, Prefix: true},
+			output:   `'x''y\\z':*D`,
+		},
+	} {
+		var tsq pgtype.TSQuery
+		err := m.Scan(pgtype.TSQueryOID, pgtype.TextFormatCode, []byte(tt.text), &tsq)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, pgtype.TSQuery{Root: tt.expected, Valid: true}, tsq, "%d", i)
+
+		buf, err := m.Encode(pgtype.TSQueryOID, pgtype.TextFormatCode, tsq, nil)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.output, string(buf), "%d", i)
+
+		buf, err = m.Encode(pgtype.TSQueryOID, pgtype.BinaryFormatCode, tsq, nil)
+		require.NoErrorf(t, err, "%d", i)
+		var fromBinary pgtype.TSQuery
+		err = m.Scan(pgtype.TSQueryOID, pgtype.BinaryFormatCode, buf, &fromBinary)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tsq, fromBinary, "%d", i)
+	}
+
+	var tsq pgtype.TSQuery
+	err := m.Scan(pgtype.TSQueryOID, pgtype.TextFormatCode, []byte(`'a' & ( 'b'`), &tsq)
+	require.Error(t, err)
+
+	_, err = m.Encode(pgtype.TSQueryOID, pgtype.BinaryFormatCode, pgtype.TSQuery{Root: &pgtype.TSQueryNode{Operator: pgtype.TSQueryAnd, Left: tsqLexeme("a")}, Valid: true}, nil)
+	require.Error(t, err)
+}
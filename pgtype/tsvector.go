@@ -0,0 +1,520 @@
+package pgtype
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+type TSVectorScanner interface {
+	ScanTSVector(v TSVector) error
+}
+
+type TSVectorValuer interface {
+	TSVectorValue() (TSVector, error)
+}
+
+// TSVectorWeight is the weight of a lexeme position in a tsvector. The zero value is D, the default weight.
+type TSVectorWeight uint8
+
+const (
+	TSVectorWeightD TSVectorWeight = iota
+	TSVectorWeightC
+	TSVectorWeightB
+	TSVectorWeightA
+)
+
+func (w TSVectorWeight) String() string {
+	switch w {
+	case TSVectorWeightA:
+		return "A"
+	case TSVectorWeightB:
+		return "B"
+	case TSVectorWeightC:
+		return "C"
+	case TSVectorWeightD:
+		return "D"
+	default:
+		return fmt.Sprintf("TSVectorWeight(%d)", uint8(w))
+	}
+}
+
+// TSVectorPosition is a position of a lexeme in a tsvector. Position must be between 1 and 16383.
+type TSVectorPosition struct {
+	Position uint16
+	Weight   TSVectorWeight
+}
+
+// TSVectorLexeme is a lexeme of a tsvector and the positions it occurs at.
+type TSVectorLexeme struct {
+	Word      string
+	Positions []TSVectorPosition
+}
+
+// TSVector represents the PostgreSQL tsvector type. PostgreSQL stores lexemes sorted and without duplicates so the
+// lexemes of a scanned TSVector are sorted by Word.
+type TSVector struct {
+	Lexemes []TSVectorLexeme
+	Valid   bool
+}
+
+func (v *TSVector) ScanTSVector(src TSVector) error {
+	*v = src
+	return nil
+}
+
+func (v TSVector) TSVectorValue() (TSVector, error) {
+	return v, nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (v *TSVector) Scan(src any) error {
+	if src == nil {
+		*v = TSVector{}
+		return nil
+	}
+
+	switch src := src.(type) {
+	case string:
+		return scanPlanTextAnyToTSVectorScanner{}.Scan([]byte(src), v)
+	}
+
+	return fmt.Errorf("cannot scan %T", src)
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (v TSVector) Value() (driver.Value, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+
+	buf, err := TSVectorCodec{}.PlanEncode(nil, 0, TextFormatCode, v).Encode(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(buf), err
+}
+
+// tsPositionMax is the largest position PostgreSQL stores in a tsvector.
+const tsPositionMax = 1<<14 - 1
+
+type TSVectorCodec struct{}
+
+func (TSVectorCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (TSVectorCodec) PreferredFormat() int16 {
+	return BinaryFormatCode
+}
+
+func (TSVectorCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	if _, ok := value.(TSVectorValuer); !ok {
+		return nil
+	}
+
+	switch format {
+	case BinaryFormatCode:
+		return encodePlanTSVectorCodecBinary{}
+	case TextFormatCode:
+		return encodePlanTSVectorCodecText{}
+	}
+
+	return nil
+}
+
+type encodePlanTSVectorCodecBinary struct{}
+
+func (encodePlanTSVectorCodecBinary) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	tsv, err := value.(TSVectorValuer).TSVectorValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !tsv.Valid {
+		return nil, nil
+	}
+
+	// PostgreSQL requires the lexemes sorted and the positions of each lexeme in ascending order.
+	lexemes := make([]TSVectorLexeme, len(tsv.Lexemes))
+	copy(lexemes, tsv.Lexemes)
+	sort.SliceStable(lexemes, func(i, j int) bool { return lexemes[i].Word < lexemes[j].Word })
+
+	buf = pgio.AppendInt32(buf, int32(len(lexemes)))
+	for _, lexeme := range lexemes {
+		if lexeme.Word == "" {
+			return nil, errors.New("tsvector lexeme must not be empty")
+		}
+		if strings.IndexByte(lexeme.Word, 0) != -1 {
+			return nil, errors.New("tsvector lexeme must not contain a NUL byte")
+		}
+
+		positions := make([]TSVectorPosition, len(lexeme.Positions))
+		copy(positions, lexeme.Positions)
+		sort.SliceStable(positions, func(i, j int) bool { return positions[i].Position < positions[j].Position })
+
+		buf = append(buf, lexeme.Word...)
+		buf = append(buf, 0)
+		buf = pgio.AppendUint16(buf, uint16(len(positions)))
+		for _, p := range positions {
+			if p.Position < 1 || p.Position > tsPositionMax {
+				return nil, fmt.Errorf("tsvector position %d out of range", p.Position)
+			}
+			if p.Weight > TSVectorWeightA {
+				return nil, fmt.Errorf("invalid tsvector weight: %d", p.Weight)
+			}
+			buf = pgio.AppendUint16(buf, uint16(p.Weight)<<14|p.Position)
+		}
+	}
+
+	return buf, nil
+}
+
+type encodePlanTSVectorCodecText struct{}
+
+func (encodePlanTSVectorCodecText) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	tsv, err := value.(TSVectorValuer).TSVectorValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !tsv.Valid {
+		return nil, nil
+	}
+
+	for i, lexeme := range tsv.Lexemes {
+		if lexeme.Word == "" {
+			return nil, errors.New("tsvector lexeme must not be empty")
+		}
+
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = appendTSQuoted(buf, lexeme.Word)
+
+		for j, p := range lexeme.Positions {
+			if p.Position < 1 || p.Position > tsPositionMax {
+				return nil, fmt.Errorf("tsvector position %d out of range", p.Position)
+			}
+			if p.Weight > TSVectorWeightA {
+				return nil, fmt.Errorf("invalid tsvector weight: %d", p.Weight)
+			}
+
+			if j == 0 {
+				buf = append(buf, ':')
+			} else {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendUint(buf, uint64(p.Position), 10)
+			if p.Weight != TSVectorWeightD {
+				buf = append(buf, p.Weight.String()...)
+			}
+		}
+	}
+
+	return buf, nil
+}
+
+// appendTSQuoted appends s quoted as a tsvector or tsquery lexeme.
+func appendTSQuoted(buf []byte, s string) []byte {
+	buf = append(buf, '\'')
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			buf = append(buf, '\'', '\'')
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		default:
+			buf = append(buf, s[i])
+		}
+	}
+	return append(buf, '\'')
+}
+
+func (TSVectorCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+
+	switch format {
+	case BinaryFormatCode:
+		switch target.(type) {
+		case TSVectorScanner:
+			return scanPlanBinaryTSVectorToTSVectorScanner{}
+		case TextScanner:
+			return scanPlanBinaryTSVectorToTextScanner{}
+		}
+	case TextFormatCode:
+		switch target.(type) {
+		case TSVectorScanner:
+			return scanPlanTextAnyToTSVectorScanner{}
+		}
+	}
+
+	return nil
+}
+
+type scanPlanBinaryTSVectorToTSVectorScanner struct{}
+
+func (scanPlanBinaryTSVectorToTSVectorScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(TSVectorScanner)
+
+	if src == nil {
+		return scanner.ScanTSVector(TSVector{})
+	}
+
+	tsv, err := decodeBinaryTSVector(src)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanTSVector(tsv)
+}
+
+type scanPlanBinaryTSVectorToTextScanner struct{}
+
+func (scanPlanBinaryTSVectorToTextScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(TextScanner)
+
+	if src == nil {
+		return scanner.ScanText(Text{})
+	}
+
+	tsv, err := decodeBinaryTSVector(src)
+	if err != nil {
+		return err
+	}
+
+	buf, err := encodePlanTSVectorCodecText{}.Encode(tsv, nil)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanText(Text{String: string(buf), Valid: true})
+}
+
+func decodeBinaryTSVector(src []byte) (TSVector, error) {
+	rp := 0
+
+	if len(src) < 4 {
+		return TSVector{}, fmt.Errorf("tsvector too short: %d", len(src))
+	}
+	lexemeCount := int(int32(binary.BigEndian.Uint32(src[rp:])))
+	rp += 4
+
+	if lexemeCount < 0 {
+		return TSVector{}, fmt.Errorf("invalid tsvector lexeme count: %d", lexemeCount)
+	}
+
+	lexemes := make([]TSVectorLexeme, 0, lexemeCount)
+	for i := 0; i < lexemeCount; i++ {
+		end := rp
+		for end < len(src) && src[end] != 0 {
+			end++
+		}
+		if end == len(src) {
+			return TSVector{}, errors.New("invalid tsvector: unterminated lexeme")
+		}
+		word := string(src[rp:end])
+		rp = end + 1
+
+		if len(src[rp:]) < 2 {
+			return TSVector{}, errors.New("invalid tsvector: missing position count")
+		}
+		positionCount := int(binary.BigEndian.Uint16(src[rp:]))
+		rp += 2
+
+		if len(src[rp:]) < positionCount*2 {
+			return TSVector{}, errors.New("invalid tsvector: missing positions")
+		}
+
+		var positions []TSVectorPosition
+		if positionCount > 0 {
+			positions = make([]TSVectorPosition, positionCount)
+			for j := range positions {
+				wep := binary.BigEndian.Uint16(src[rp:])
+				rp += 2
+				positions[j] = TSVectorPosition{Position: wep & tsPositionMax, Weight: TSVectorWeight(wep >> 14)}
+			}
+		}
+
+		lexemes = append(lexemes, TSVectorLexeme{Word: word, Positions: positions})
+	}
+
+	if rp != len(src) {
+		return TSVector{}, fmt.Errorf("invalid tsvector: %d extra bytes", len(src)-rp)
+	}
+
+	return TSVector{Lexemes: lexemes, Valid: true}, nil
+}
+
+type scanPlanTextAnyToTSVectorScanner struct{}
+
+func (scanPlanTextAnyToTSVectorScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(TSVectorScanner)
+
+	if src == nil {
+		return scanner.ScanTSVector(TSVector{})
+	}
+
+	tsv, err := parseTSVector(string(src))
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanTSVector(tsv)
+}
+
+// parseTSVector parses the text format of a tsvector, e.g. 'fat':2A 'rat':3.
+func parseTSVector(s string) (TSVector, error) {
+	p := tsParser{s: s}
+	lexemes := []TSVectorLexeme{}
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+
+		word, err := p.readLexeme()
+		if err != nil {
+			return TSVector{}, err
+		}
+
+		lexeme := TSVectorLexeme{Word: word}
+		if !p.eof() && p.s[p.pos] == ':' {
+			p.pos++
+			for {
+				start := p.pos
+				for !p.eof() && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+					p.pos++
+				}
+				n, err := strconv.ParseUint(p.s[start:p.pos], 10, 16)
+				if err != nil {
+					return TSVector{}, fmt.Errorf("invalid tsvector position: %v", err)
+				}
+
+				position := TSVectorPosition{Position: uint16(n)}
+				if !p.eof() {
+					switch p.s[p.pos] {
+					case 'A', 'a', '*':
+						position.Weight = TSVectorWeightA
+						p.pos++
+					case 'B', 'b':
+						position.Weight = TSVectorWeightB
+						p.pos++
+					case 'C', 'c':
+						position.Weight = TSVectorWeightC
+						p.pos++
+					case 'D', 'd':
+						p.pos++
+					}
+				}
+				lexeme.Positions = append(lexeme.Positions, position)
+
+				if p.eof() || p.s[p.pos] != ',' {
+					break
+				}
+				p.pos++
+			}
+		}
+
+		lexemes = append(lexemes, lexeme)
+	}
+
+	return TSVector{Lexemes: lexemes, Valid: true}, nil
+}
+
+// tsParser is a parser for the text formats of tsvector and tsquery.
+type tsParser struct {
+	s   string
+	pos int
+}
+
+func (p *tsParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *tsParser) skipSpace() {
+	for !p.eof() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+// readLexeme reads a quoted or unquoted lexeme. Unquoted lexemes end at whitespace or any character in
+// tsLexemeTerminators.
+func (p *tsParser) readLexeme() (string, error) {
+	var sb strings.Builder
+
+	if p.s[p.pos] == '\'' {
+		p.pos++
+		for {
+			if p.eof() {
+				return "", errors.New("unterminated quoted lexeme")
+			}
+			c := p.s[p.pos]
+			p.pos++
+			switch c {
+			case '\'':
+				if !p.eof() && p.s[p.pos] == '\'' {
+					sb.WriteByte('\'')
+					p.pos++
+				} else {
+					return sb.String(), nil
+				}
+			case '\\':
+				if p.eof() {
+					return "", errors.New("unterminated escape in lexeme")
+				}
+				sb.WriteByte(p.s[p.pos])
+				p.pos++
+			default:
+				sb.WriteByte(c)
+			}
+		}
+	}
+
+	for !p.eof() {
+		c := p.s[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || strings.IndexByte(tsLexemeTerminators, c) != -1 {
+			break
+		}
+		if c == '\\' {
+			p.pos++
+			if p.eof() {
+				return "", errors.New("unterminated escape in lexeme")
+			}
+			c = p.s[p.pos]
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("expected lexeme at position %d", p.pos)
+	}
+
+	return sb.String(), nil
+}
+
+const tsLexemeTerminators = ":'()!&|<"
+
+func (c TSVectorCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	return codecDecodeToTextFormat(c, m, oid, format, src)
+}
+
+func (c TSVectorCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	var tsv TSVector
+	err := codecScan(c, m, oid, format, src, &tsv)
+	if err != nil {
+		return nil, err
+	}
+	return tsv, nil
+}
@@ -0,0 +1,97 @@
+package pgtype_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func isExpectedEqTSVector(a any) func(any) bool {
+	return func(v any) bool {
+		return reflect.DeepEqual(a, v)
+	}
+}
+
+func TestTSVectorCodec(t *testing.T) {
+	skipCockroachDB(t, "Server does not support type tsvector")
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "tsvector", []pgxtest.ValueRoundTripTest{
+		{
+			pgtype.TSVector{Lexemes: []pgtype.TSVectorLexeme{}, Valid: true},
+			new(pgtype.TSVector),
+			isExpectedEqTSVector(pgtype.TSVector{Lexemes: []pgtype.TSVectorLexeme{}, Valid: true}),
+		},
+		{
+			pgtype.TSVector{
+				Lexemes: []pgtype.TSVectorLexeme{
+					{Word: "rat", Positions: []pgtype.TSVectorPosition{{Position: 3}}},
+					{Word: "fat", Positions: []pgtype.TSVectorPosition{{Position: 2, Weight: pgtype.TSVectorWeightA}, {Position: 7, Weight: pgtype.TSVectorWeightC}}},
+					{Word: "it's", Positions: nil},
+					{Word: `back\slash`, Positions: []pgtype.TSVectorPosition{{Position: 16383, Weight: pgtype.TSVectorWeightB}}},
+				},
+				Valid: true,
+			},
+			new(pgtype.TSVector),
+			isExpectedEqTSVector(pgtype.TSVector{
+				Lexemes: []pgtype.TSVectorLexeme{
+					{Word: `back\slash`, Positions: []pgtype.TSVectorPosition{{Position: 16383, Weight: pgtype.TSVectorWeightB}}},
+					{Word: "fat", Positions: []pgtype.TSVectorPosition{{Position: 2, Weight: pgtype.TSVectorWeightA}, {Position: 7, Weight: pgtype.TSVectorWeightC}}},
+					{Word: "it's", Positions: nil},
+					{Word: "rat", Positions: []pgtype.TSVectorPosition{{Position: 3}}},
+				},
+				Valid: true,
+			}),
+		},
+		{
+			pgtype.TSVector{
+				Lexemes: []pgtype.TSVectorLexeme{
+					{Word: "fat", Positions: []pgtype.TSVectorPosition{{Position: 2, Weight: pgtype.TSVectorWeightA}}},
+					{Word: "it's"},
+				},
+				Valid: true,
+			},
+			new(string),
+			isExpectedEq(`'fat':2A 'it''s'`),
+		},
+		{pgtype.TSVector{}, new(pgtype.TSVector), isExpectedEqTSVector(pgtype.TSVector{})},
+		{nil, new(pgtype.TSVector), isExpectedEqTSVector(pgtype.TSVector{})},
+	})
+}
+
+func TestTSVectorTextRoundTrip(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var tsv pgtype.TSVector
+	err := m.Scan(pgtype.TSVectorOID, pgtype.TextFormatCode, []byte(`'a':1,3B 'don''t' plain:4c 'x\\y':2*`), &tsv)
+	require.NoError(t, err)
+	require.Equal(t, pgtype.TSVector{
+		Lexemes: []pgtype.TSVectorLexeme{
+			{Word: "a", Positions: []pgtype.TSVectorPosition{{Position: 1}, {Position: 3, Weight: pgtype.TSVectorWeightB}}},
+			{Word: "don't"},
+			{Word: "plain", Positions: []pgtype.TSVectorPosition{{Position: 4, Weight: pgtype.TSVectorWeightC}}},
+			{Word: `x\y`, Positions: []pgtype.TSVectorPosition{{Position: 2, Weight: pgtype.TSVectorWeightA}}},
+		},
+		Valid: true,
+	}, tsv)
+
+	buf, err := m.Encode(pgtype.TSVectorOID, pgtype.TextFormatCode, tsv, nil)
+	require.NoError(t, err)
+	require.Equal(t, `'a':1,3B 'don''t' 'plain':4C 'x\\y':2A`, string(buf))
+
+	buf, err = m.Encode(pgtype.TSVectorOID, pgtype.BinaryFormatCode, tsv, nil)
+	require.NoError(t, err)
+	var fromBinary pgtype.TSVector
+	err = m.Scan(pgtype.TSVectorOID, pgtype.BinaryFormatCode, buf, &fromBinary)
+	require.NoError(t, err)
+	require.Equal(t, tsv, fromBinary)
+
+	_, err = m.Encode(pgtype.TSVectorOID, pgtype.BinaryFormatCode, pgtype.TSVector{
+		Lexemes: []pgtype.TSVectorLexeme{{Word: "a", Positions: []pgtype.TSVectorPosition{{Position: 0}}}},
+		Valid:   true,
+	}, nil)
+	require.Error(t, err)
+}
@@ -19,6 +19,10 @@ type Uint32Valuer interface {
 }
 
 // Uint32 is the core type that is used to represent PostgreSQL types such as OID, CID, and XID.
+//
+// An XID is a 32-bit transaction id that wraps around, so XIDs are only meaningful relative to each other within about
+// 2^31 transactions and must not be compared numerically across a wraparound. Use xid8 (see Uint64) when a
+// monotonically increasing transaction id is needed, e.g. the result of pg_current_xact_id().
 type Uint32 struct {
 	Uint32 uint32
 	Valid  bool
@@ -100,6 +104,8 @@ func (Uint32Codec) PlanEncode(m *Map, oid uint32, format int16, value any) Encod
 		switch value.(type) {
 		case uint32:
 			return encodePlanUint32CodecTextUint32{}
+		case Uint32Valuer:
+			return encodePlanUint32CodecTextUint32Valuer{}
 		case Int64Valuer:
 			return encodePlanUint32CodecTextInt64Valuer{}
 		}
@@ -0,0 +1,45 @@
+package pgtype_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUint64Codec(t *testing.T) {
+	skipPostgreSQLVersionLessThan(t, 13)
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, "xid8", []pgxtest.ValueRoundTripTest{
+		{
+			pgtype.Uint64{Uint64: 1 << 36, Valid: true},
+			new(pgtype.Uint64),
+			isExpectedEq(pgtype.Uint64{Uint64: 1 << 36, Valid: true}),
+		},
+		{uint64(math.MaxUint64), new(uint64), isExpectedEq(uint64(math.MaxUint64))},
+		{pgtype.Uint64{}, new(pgtype.Uint64), isExpectedEq(pgtype.Uint64{})},
+		{nil, new(pgtype.Uint64), isExpectedEq(pgtype.Uint64{})},
+	})
+}
+
+func TestUint64CodecEncodeDecode(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		buf, err := m.Encode(pgtype.XID8OID, format, uint64(math.MaxUint64), nil)
+		require.NoError(t, err)
+
+		var n uint64
+		err = m.Scan(pgtype.XID8OID, format, buf, &n)
+		require.NoError(t, err)
+		require.Equal(t, uint64(math.MaxUint64), n)
+
+		var u pgtype.Uint64
+		err = m.Scan(pgtype.XID8OID, format, buf, &u)
+		require.NoError(t, err)
+		require.Equal(t, pgtype.Uint64{Uint64: math.MaxUint64, Valid: true}, u)
+	}
+}
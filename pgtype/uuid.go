@@ -15,6 +15,13 @@ type UUIDValuer interface {
 	UUIDValue() (UUID, error)
 }
 
+// UUIDDecoder is implemented by types that can decode themselves from the 16 byte form of a UUID. It allows UUID types
+// from other packages to be scanned directly without pgx depending on those packages. NULL cannot be scanned into a
+// UUIDDecoder.
+type UUIDDecoder interface {
+	DecodeUUID(v [16]byte) error
+}
+
 type UUID struct {
 	Bytes [16]byte
 	Valid bool
@@ -173,6 +180,8 @@ func (UUIDCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan
 		switch target.(type) {
 		case UUIDScanner:
 			return scanPlanBinaryUUIDToUUIDScanner{}
+		case UUIDDecoder:
+			return scanPlanBinaryUUIDToUUIDDecoder{}
 		case TextScanner:
 			return scanPlanBinaryUUIDToTextScanner{}
 		}
@@ -180,6 +189,8 @@ func (UUIDCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan
 		switch target.(type) {
 		case UUIDScanner:
 			return scanPlanTextAnyToUUIDScanner{}
+		case UUIDDecoder:
+			return scanPlanTextAnyToUUIDDecoder{}
 		}
 	}
 
@@ -205,6 +216,25 @@ func (scanPlanBinaryUUIDToUUIDScanner) Scan(src []byte, dst any) error {
 	return scanner.ScanUUID(uuid)
 }
 
+type scanPlanBinaryUUIDToUUIDDecoder struct{}
+
+func (scanPlanBinaryUUIDToUUIDDecoder) Scan(src []byte, dst any) error {
+	decoder := (dst).(UUIDDecoder)
+
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	if len(src) != 16 {
+		return fmt.Errorf("invalid length for UUID: %v", len(src))
+	}
+
+	var buf [16]byte
+	copy(buf[:], src)
+
+	return decoder.DecodeUUID(buf)
+}
+
 type scanPlanBinaryUUIDToTextScanner struct{}
 
 func (scanPlanBinaryUUIDToTextScanner) Scan(src []byte, dst any) error {
@@ -241,6 +271,23 @@ func (scanPlanTextAnyToUUIDScanner) Scan(src []byte, dst any) error {
 	return scanner.ScanUUID(UUID{Bytes: buf, Valid: true})
 }
 
+type scanPlanTextAnyToUUIDDecoder struct{}
+
+func (scanPlanTextAnyToUUIDDecoder) Scan(src []byte, dst any) error {
+	decoder := (dst).(UUIDDecoder)
+
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	buf, err := parseUUID(string(src))
+	if err != nil {
+		return err
+	}
+
+	return decoder.DecodeUUID(buf)
+}
+
 func (c UUIDCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
 	if src == nil {
 		return nil, nil
@@ -130,3 +130,30 @@ func TestUUID_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+type uuidDecoderTest struct {
+	bytes [16]byte
+}
+
+func (u *uuidDecoderTest) DecodeUUID(v [16]byte) error {
+	u.bytes = v
+	return nil
+}
+
+func TestUUIDCodecScanUUIDDecoder(t *testing.T) {
+	m := pgtype.NewMap()
+	expected := [16]byte{29, 72, 90, 122, 109, 24, 69, 153, 140, 108, 52, 66, 86, 22, 136, 122}
+
+	var binary uuidDecoderTest
+	err := m.Scan(pgtype.UUIDOID, pgtype.BinaryFormatCode, expected[:], &binary)
+	require.NoError(t, err)
+	require.Equal(t, expected, binary.bytes)
+
+	var text uuidDecoderTest
+	err = m.Scan(pgtype.UUIDOID, pgtype.TextFormatCode, []byte("1d485a7a-6d18-4599-8c6c-34425616887a"), &text)
+	require.NoError(t, err)
+	require.Equal(t, expected, text.bytes)
+
+	err = m.Scan(pgtype.UUIDOID, pgtype.BinaryFormatCode, nil, &binary)
+	require.Error(t, err)
+}
@@ -1,6 +1,10 @@
 package pgxpool
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -50,3 +54,33 @@ func (br *poolBatchResults) Close() error {
 	}
 	return err
 }
+
+// trackedBatchResults wraps pgx.BatchResults to report the batch to OnSlowQuery and QueryLogger when it is closed.
+type trackedBatchResults struct {
+	pgx.BatchResults
+	ctx       context.Context
+	p         *Pool
+	b         *pgx.Batch
+	startTime time.Time
+	checked   bool
+}
+
+func (br *trackedBatchResults) Close() error {
+	err := br.BatchResults.Close()
+	if !br.checked {
+		br.checked = true
+		duration := time.Since(br.startTime)
+		if duration > br.p.slowQueryThreshold || br.p.queryLogger != nil {
+			sqls := make([]string, br.b.Len())
+			var args []any
+			for i := range sqls {
+				sqls[i] = br.b.ItemSQL(i)
+				args = append(args, br.b.ItemArguments(i)...)
+			}
+			sql := strings.Join(sqls, "; ")
+			br.p.checkSlowQuery(br.ctx, sql, duration, nil)
+			br.p.logQuery(br.ctx, sql, duration, args, err)
+		}
+	}
+	return err
+}
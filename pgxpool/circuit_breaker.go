@@ -0,0 +1,83 @@
+package pgxpool
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Acquire when the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker configures failing fast when the database is unreachable. After FailureThreshold consecutive failed
+// connection attempts the circuit opens and Acquire returns ErrCircuitOpen for CooldownDuration. After the cooldown
+// one Acquire at a time is allowed as a trial. Only a new connection proves the database is reachable: if the trial
+// establishes one the circuit closes and if that fails the circuit opens for another cooldown. A trial that is served
+// by an idle connection or whose context is done leaves the circuit half-open for the next trial. A FailureThreshold of
+// 0 disables the circuit breaker.
+type CircuitBreaker struct {
+	FailureThreshold int
+	CooldownDuration time.Duration
+}
+
+type circuitBreakerState struct {
+	config CircuitBreaker
+
+	mux                 sync.Mutex
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+}
+
+// beginAcquire returns ErrCircuitOpen if Acquire must fail fast. Otherwise it returns whether the caller is the trial
+// after a cooldown and must call endProbe when it is done.
+func (cb *circuitBreakerState) beginAcquire() (probe bool, err error) {
+	if cb.config.FailureThreshold <= 0 {
+		return false, nil
+	}
+
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+
+	if cb.openedAt.IsZero() {
+		return false, nil
+	}
+
+	if cb.probing || time.Since(cb.openedAt) < cb.config.CooldownDuration {
+		return false, ErrCircuitOpen
+	}
+
+	cb.probing = true
+	return true, nil
+}
+
+// endProbe allows the next trial Acquire. The result of the trial is recorded by recordConnect if it established a
+// new connection.
+func (cb *circuitBreakerState) endProbe() {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+
+	cb.probing = false
+}
+
+// recordConnect records the result of a connection attempt. A failure after the cooldown opens the circuit again.
+func (cb *circuitBreakerState) recordConnect(err error) {
+	if cb.config.FailureThreshold <= 0 {
+		return
+	}
+
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+
+	if err == nil {
+		cb.consecutiveFailures = 0
+		cb.openedAt = time.Time{}
+		return
+	}
+
+	cb.consecutiveFailures++
+	if cb.consecutiveFailures >= cb.config.FailureThreshold &&
+		(cb.openedAt.IsZero() || time.Since(cb.openedAt) >= cb.config.CooldownDuration) {
+		cb.openedAt = time.Now()
+	}
+}
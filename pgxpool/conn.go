@@ -2,7 +2,10 @@ package pgxpool
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -26,7 +29,10 @@ func (c *Conn) Release() {
 	res := c.res
 	c.res = nil
 
+	c.p.connAcquired(res.Value(), -1)
+
 	if conn.IsClosed() || conn.PgConn().IsBusy() || conn.PgConn().TxStatus() != 'I' {
+		atomic.AddInt64(&c.p.brokenConnDestroyCount, 1)
 		res.Destroy()
 		// Signal to the health check to run since we just destroyed a connections
 		// and we might be below minConns now
@@ -74,29 +80,84 @@ func (c *Conn) Hijack() *pgx.Conn {
 	res := c.res
 	c.res = nil
 
+	c.p.connAcquired(res.Value(), -1)
+	c.p.hostConnAdded(res.Value().host, -1)
+
 	res.Hijack()
 
 	return conn
 }
 
 func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	return c.Conn().Exec(ctx, sql, arguments...)
+	if !c.p.tracksQueries() {
+		return c.Conn().Exec(ctx, sql, arguments...)
+	}
+
+	startTime := time.Now()
+	commandTag, err := c.Conn().Exec(ctx, sql, arguments...)
+	duration := time.Since(startTime)
+	c.p.checkSlowQuery(ctx, sql, duration, arguments)
+	c.p.logQuery(ctx, sql, duration, arguments, err)
+	return commandTag, err
 }
 
 func (c *Conn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	return c.Conn().Query(ctx, sql, args...)
+	if !c.p.tracksQueries() {
+		return c.Conn().Query(ctx, sql, args...)
+	}
+
+	startTime := time.Now()
+	rows, err := c.Conn().Query(ctx, sql, args...)
+	trackedRows := &trackedRows{Rows: rows, ctx: ctx, p: c.p, sql: sql, args: args, startTime: startTime}
+	if err != nil {
+		// The rows are already closed and may never be closed again by the caller.
+		trackedRows.check()
+	}
+	return trackedRows, err
 }
 
 func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	return c.Conn().QueryRow(ctx, sql, args...)
+	if !c.p.tracksQueries() {
+		return c.Conn().QueryRow(ctx, sql, args...)
+	}
+
+	rows, _ := c.Query(ctx, sql, args...)
+	return trackedRow{rows: rows}
 }
 
 func (c *Conn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
-	return c.Conn().SendBatch(ctx, b)
+	if !c.p.tracksQueries() {
+		return c.Conn().SendBatch(ctx, b)
+	}
+
+	startTime := time.Now()
+	br := c.Conn().SendBatch(ctx, b)
+	return &trackedBatchResults{BatchResults: br, ctx: ctx, p: c.p, b: b, startTime: startTime}
 }
 
 func (c *Conn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
-	return c.Conn().CopyFrom(ctx, tableName, columnNames, rowSrc)
+	if c.p.queryLogger == nil {
+		return c.Conn().CopyFrom(ctx, tableName, columnNames, rowSrc)
+	}
+
+	startTime := time.Now()
+	n, err := c.Conn().CopyFrom(ctx, tableName, columnNames, rowSrc)
+	c.p.logQuery(ctx, copyFromSQL(tableName, columnNames), time.Since(startTime), nil, err)
+	return n, err
+}
+
+// copyFromSQL returns the COPY statement logged for a CopyFrom.
+func copyFromSQL(tableName pgx.Identifier, columnNames []string) string {
+	quotedColumnNames := make([]string, len(columnNames))
+	for i, name := range columnNames {
+		quotedColumnNames[i] = pgx.Identifier{name}.Sanitize()
+	}
+	return fmt.Sprintf("copy %s (%s) from stdin binary", tableName.Sanitize(), strings.Join(quotedColumnNames, ", "))
+}
+
+// CopyFromRows is shorthand for CopyFrom with pgx.CopyFromRows(rows).
+func (c *Conn) CopyFromRows(ctx context.Context, tableName pgx.Identifier, columnNames []string, rows [][]any) (int64, error) {
+	return c.CopyFrom(ctx, tableName, columnNames, pgx.CopyFromRows(rows))
 }
 
 // Begin starts a transaction block from the *Conn without explicitly setting a transaction mode (see BeginTx with TxOptions if transaction mode is required).
@@ -109,10 +170,25 @@ func (c *Conn) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, er
 	return c.Conn().BeginTx(ctx, txOptions)
 }
 
+// BeginTxFuncRetry starts a transaction from the *Conn with txOptions and calls f, retrying serialization failures and
+// deadlocks up to maxAttempts attempts in total. See pgx.BeginTxFuncRetry.
+func (c *Conn) BeginTxFuncRetry(ctx context.Context, txOptions pgx.TxOptions, maxAttempts int, f func(pgx.Tx) error) error {
+	return pgx.BeginTxFuncRetry(ctx, c, txOptions, maxAttempts, f)
+}
+
 func (c *Conn) Ping(ctx context.Context) error {
 	return c.Conn().Ping(ctx)
 }
 
+// WaitForNotification waits for a PostgreSQL notification on c. See pgx.Conn.WaitForNotification.
+//
+// A connection that has executed LISTEN should not be released until it has executed UNLISTEN. Otherwise it returns to
+// the pool still listening and notifications are delivered to whichever caller acquires it next. Listener manages a
+// listening connection, including replacing it if it fails.
+func (c *Conn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	return c.Conn().WaitForNotification(ctx)
+}
+
 func (c *Conn) Conn() *pgx.Conn {
 	return c.connResource().conn
 }
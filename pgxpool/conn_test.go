@@ -4,7 +4,9 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/require"
 )
@@ -78,3 +80,83 @@ func TestConnCopyFrom(t *testing.T) {
 
 	testCopyFrom(t, c)
 }
+
+func TestConnCopyFromRows(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer c.Release()
+
+	_, err = c.Exec(context.Background(), `create temporary table foo(a int4, b text)`)
+	require.NoError(t, err)
+
+	copyCount, err := c.CopyFromRows(context.Background(), pgx.Identifier{"foo"}, []string{"a", "b"}, [][]any{{int32(1), "a"}, {nil, nil}})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, copyCount)
+
+	var n int64
+	err = c.QueryRow(context.Background(), "select count(*) from foo").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+}
+
+func TestConnWaitForNotification(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	listener, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer listener.Release()
+
+	_, err = listener.Exec(context.Background(), "listen pgxpool_conn_notify")
+	require.NoError(t, err)
+	defer listener.Exec(context.Background(), "unlisten pgxpool_conn_notify")
+
+	notifier, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer notifier.Release()
+
+	_, err = notifier.Exec(context.Background(), "select pg_notify('pgxpool_conn_notify', 'hello')")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	n, err := listener.WaitForNotification(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "pgxpool_conn_notify", n.Channel)
+	require.Equal(t, "hello", n.Payload)
+	require.Equal(t, notifier.Conn().PgConn().PID(), n.PID)
+}
+
+func TestConnBeginTxFuncRetry(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer c.Release()
+
+	attempts := 0
+	err = c.BeginTxFuncRetry(context.Background(), pgx.TxOptions{IsoLevel: pgx.Serializable}, 3, func(tx pgx.Tx) error {
+		attempts++
+		if attempts < 3 {
+			_, err := tx.Exec(context.Background(), "do $$ begin raise exception 'retry me' using errcode = 'serialization_failure'; end $$")
+			return err
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+}
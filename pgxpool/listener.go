@@ -0,0 +1,204 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Listener receives notifications from any number of channels over a single connection acquired from a Pool. The
+// connection is held by the Listener until Close is called. If the connection fails it is replaced with a new
+// connection and all active channels are listened to again. Notifications sent while the connection was being
+// replaced are lost.
+//
+// Listen and Unlisten may be called concurrently with Next. Next must not be called concurrently with itself.
+type Listener struct {
+	pool *Pool
+
+	mux        sync.Mutex
+	conn       *Conn
+	channels   map[string]struct{}
+	waitCancel context.CancelFunc
+	waitDone   chan struct{}
+	closed     bool
+}
+
+// NewListener returns a new Listener that acquires its connection from pool. The connection is not acquired until
+// Listen or Next is called.
+func NewListener(pool *Pool) *Listener {
+	return &Listener{
+		pool:     pool,
+		channels: make(map[string]struct{}),
+	}
+}
+
+var errListenerClosed = errors.New("listener closed")
+
+// Listen starts listening to channel. It is safe to call Listen for a channel that is already being listened to.
+func (l *Listener) Listen(ctx context.Context, channel string) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.closed {
+		return errListenerClosed
+	}
+
+	l.interruptWait()
+
+	if l.conn == nil {
+		l.channels[channel] = struct{}{}
+		err := l.connect(ctx)
+		if err != nil {
+			delete(l.channels, channel)
+		}
+		return err
+	}
+
+	_, err := l.conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		l.releaseIfClosed()
+		return err
+	}
+	l.channels[channel] = struct{}{}
+
+	return nil
+}
+
+// Unlisten stops listening to channel.
+func (l *Listener) Unlisten(ctx context.Context, channel string) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.closed {
+		return errListenerClosed
+	}
+
+	l.interruptWait()
+
+	delete(l.channels, channel)
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.Exec(ctx, "unlisten "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		l.releaseIfClosed()
+	}
+	return err
+}
+
+// Next waits for the next notification on any listened to channel. The channel is available as the Channel field of
+// the returned notification.
+func (l *Listener) Next(ctx context.Context) (*pgconn.Notification, error) {
+	for {
+		l.mux.Lock()
+		if l.closed {
+			l.mux.Unlock()
+			return nil, errListenerClosed
+		}
+
+		if l.conn == nil {
+			err := l.connect(ctx)
+			if err != nil {
+				l.mux.Unlock()
+				return nil, err
+			}
+		}
+
+		conn := l.conn
+		waitCtx, cancel := context.WithCancel(ctx)
+		waitDone := make(chan struct{})
+		l.waitCancel = cancel
+		l.waitDone = waitDone
+		l.mux.Unlock()
+
+		n, err := conn.Conn().WaitForNotification(waitCtx)
+		cancel()
+		close(waitDone)
+		if n != nil {
+			return n, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		l.mux.Lock()
+		if l.conn == conn {
+			l.releaseIfClosed()
+		}
+		replaced := l.conn != conn
+		l.mux.Unlock()
+
+		// The wait was interrupted by Listen or Unlisten or the connection failed and will be replaced.
+		if waitCtx.Err() != nil || replaced {
+			continue
+		}
+
+		return nil, err
+	}
+}
+
+// Close stops listening to all channels and returns the connection to the pool.
+func (l *Listener) Close(ctx context.Context) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	l.interruptWait()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.Exec(ctx, "unlisten *")
+	l.conn.Release()
+	l.conn = nil
+
+	return err
+}
+
+// connect acquires a connection and listens to all channels. l.mux must be held.
+func (l *Listener) connect(ctx context.Context) error {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	for channel := range l.channels {
+		_, err := conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			conn.Release()
+			return err
+		}
+	}
+
+	l.conn = conn
+	return nil
+}
+
+// releaseIfClosed releases the connection if it has been closed so it will be replaced when next needed. l.mux must be
+// held.
+func (l *Listener) releaseIfClosed() {
+	if l.conn != nil && l.conn.Conn().IsClosed() {
+		l.conn.Release()
+		l.conn = nil
+	}
+}
+
+// interruptWait interrupts a Next in progress and waits for it to stop using the connection. l.mux must be held.
+func (l *Listener) interruptWait() {
+	if l.waitCancel != nil {
+		l.waitCancel()
+		<-l.waitDone
+		l.waitCancel = nil
+		l.waitDone = nil
+	}
+}
@@ -0,0 +1,55 @@
+package pgxpool_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	listener := pgxpool.NewListener(pool)
+	defer listener.Close(ctx)
+
+	require.NoError(t, listener.Listen(ctx, "listener_a"))
+	require.NoError(t, listener.Listen(ctx, "listener_b"))
+
+	_, err = pool.Exec(ctx, "select pg_notify('listener_a', 'one'), pg_notify('listener_b', 'two')")
+	require.NoError(t, err)
+
+	n, err := listener.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "listener_a", n.Channel)
+	require.Equal(t, "one", n.Payload)
+
+	n, err = listener.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "listener_b", n.Channel)
+	require.Equal(t, "two", n.Payload)
+
+	require.NoError(t, listener.Unlisten(ctx, "listener_a"))
+
+	// Listen while Next is waiting.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		listener.Listen(ctx, "listener_c")
+		pool.Exec(ctx, "select pg_notify('listener_a', 'ignored'), pg_notify('listener_c', 'three')")
+	}()
+
+	n, err = listener.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "listener_c", n.Channel)
+	require.Equal(t, "three", n.Payload)
+}
@@ -2,6 +2,7 @@ package pgxpool
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"runtime"
@@ -21,12 +22,19 @@ var defaultMaxConnLifetime = time.Hour
 var defaultMaxConnIdleTime = time.Minute * 30
 var defaultHealthCheckPeriod = time.Minute
 
+// ErrMaxConnsReached occurs when AcquireNew cannot open a connection without exceeding MaxConns.
+var ErrMaxConnsReached = errors.New("pool has reached MaxConns")
+
+// ErrAcquireTimeout occurs when AcquireWithTimeout cannot acquire a connection within its timeout.
+var ErrAcquireTimeout = errors.New("timeout acquiring connection from pool")
+
 type connResource struct {
 	conn       *pgx.Conn
 	conns      []Conn
 	poolRows   []poolRow
 	poolRowss  []poolRows
 	maxAgeTime time.Time
+	host       string
 }
 
 func (cr *connResource) getConn(p *Pool, res *puddle.Resource[*connResource]) *Conn {
@@ -40,6 +48,8 @@ func (cr *connResource) getConn(p *Pool, res *puddle.Resource[*connResource]) *C
 	c.res = res
 	c.p = p
 
+	p.connAcquired(cr, 1)
+
 	return c
 }
 
@@ -75,9 +85,11 @@ func (cr *connResource) getPoolRows(c *Conn, r pgx.Rows) *poolRows {
 type Pool struct {
 	// 64 bit fields accessed with atomics must be at beginning of struct to guarantee alignment for certain 32-bit
 	// architectures. See BUGS section of https://pkg.go.dev/sync/atomic and https://github.com/jackc/pgx/issues/1288.
-	newConnsCount        int64
-	lifetimeDestroyCount int64
-	idleDestroyCount     int64
+	newConnsCount           int64
+	lifetimeDestroyCount    int64
+	idleDestroyCount        int64
+	healthCheckDestroyCount int64
+	brokenConnDestroyCount  int64
 
 	p                     *puddle.Pool[*connResource]
 	config                *Config
@@ -85,6 +97,12 @@ type Pool struct {
 	afterConnect          func(context.Context, *pgx.Conn) error
 	beforeAcquire         func(context.Context, *pgx.Conn) bool
 	afterRelease          func(*pgx.Conn) bool
+	retryPolicy           func(error, int) bool
+	defaultQueryTimeout   time.Duration
+	slowQueryThreshold    time.Duration
+	onSlowQuery           func(context.Context, string, time.Duration, []any)
+	queryLogger           func(context.Context, QueryLogEntry)
+	logQueryArgs          bool
 	minConns              int32
 	maxConns              int32
 	maxConnLifetime       time.Duration
@@ -94,6 +112,14 @@ type Pool struct {
 
 	healthCheckChan chan struct{}
 
+	acquireNewConns int32 // connections currently open by AcquireNew
+
+	circuitBreaker circuitBreakerState
+
+	hostStatsMux  sync.Mutex
+	hostStats     map[string]*HostStat
+	acquiredConns map[*connResource]struct{} // guarded by hostStatsMux
+
 	closeOnce sync.Once
 	closeChan chan struct{}
 }
@@ -110,16 +136,74 @@ type Config struct {
 	// AfterConnect is called after a connection is established, but before it is added to the pool.
 	AfterConnect func(context.Context, *pgx.Conn) error
 
+	// OnAuthFailure is called when establishing a new connection fails with an authentication error (SQLSTATE 28P01
+	// or 28000). It should return a config with refreshed credentials (e.g. a copy of ConnConfig with a new password).
+	// The connection is retried once with the returned config. This handles credentials such as tokens expiring while a
+	// connection is being established. The returned config is only used for the retry and does not replace ConnConfig.
+	// BeforeConnect is called on a copy of the returned config before the retry. It must not return a nil config.
+	OnAuthFailure func(context.Context) (*pgx.ConnConfig, error)
+
+	// PreparedStatements is a map of statement names to SQL that are prepared on every new connection after AfterConnect.
+	// All statements are prepared in a single round trip with pgx.Conn.PrepareAll.
+	// This avoids the latency of preparing commonly used statements on demand when a connection is replaced. The
+	// statements can be executed by passing their name as the sql argument of Exec, Query, or QueryRow.
+	PreparedStatements map[string]string
+
+	// PgBouncerMode configures connections for use behind PgBouncer in transaction pooling mode or a similar proxy
+	// where consecutive transactions may run on different server sessions. Named prepared statements are not used:
+	// the statement cache is disabled and the default query exec mode is QueryExecModeCacheDescribe, which only uses
+	// the unnamed statement. It is an error to combine PgBouncerMode with PreparedStatements. Session state such as
+	// LISTEN and session level SET commands must not be relied on in this mode.
+	PgBouncerMode bool
+
 	// BeforeAcquire is called before a connection is acquired from the pool. It must return true to allow the
 	// acquision or false to indicate that the connection should be destroyed and a different connection should be
-	// acquired.
+	// acquired. Acquire keeps trying connections until one is accepted or the context passed to Acquire is done, so a
+	// context without a deadline waits indefinitely if BeforeAcquire never returns true.
 	BeforeAcquire func(context.Context, *pgx.Conn) bool
 
 	// AfterRelease is called after a connection is released, but before it is returned to the pool. It must return true to
 	// return the connection to the pool or false to destroy the connection.
 	AfterRelease func(*pgx.Conn) bool
 
-	// MaxConnLifetime is the duration since creation after which a connection will be automatically closed.
+	// RetryPolicy is called when Exec or Query fails. err is the error and attempt is the number of attempts made so
+	// far, starting at 1. If it returns true the query is retried on a newly acquired connection. The policy is only
+	// consulted when the failed attempt is known to be safe to retry (i.e. the query was not sent to the server) or the
+	// query was marked with QueryIdempotent. The context is checked before each retry.
+	RetryPolicy func(err error, attempt int) bool
+
+	// DefaultQueryTimeout is the timeout applied to Exec, Query, and QueryRow on the pool when their context has no
+	// deadline. It covers acquiring the connection and executing the query. For Query and QueryRow it also covers
+	// reading the rows. A context with a deadline is used as is. Zero disables the default timeout. It does not apply
+	// to connections acquired with Acquire.
+	DefaultQueryTimeout time.Duration
+
+	// SlowQueryThreshold is the duration a query must exceed for OnSlowQuery to be called.
+	SlowQueryThreshold time.Duration
+
+	// OnSlowQuery is called when an Exec, Query, or SendBatch on a connection from the pool takes longer than
+	// SlowQueryThreshold. The duration of a Query is measured until its Rows are closed and the duration of a SendBatch is
+	// measured until its BatchResults are closed. For a batch, sql is the SQL of all queued queries separated by
+	// semicolons and args is nil.
+	OnSlowQuery func(ctx context.Context, sql string, duration time.Duration, args []any)
+
+	// QueryLogger is called after each Exec, Query, QueryRow, SendBatch, and CopyFrom on a connection from the pool with
+	// the SQL, number of arguments, duration, and error of the call. Durations are measured in the same way as for
+	// OnSlowQuery. Argument values are only included if LogQueryArgs is true. It is nil by default.
+	QueryLogger func(ctx context.Context, entry QueryLogEntry)
+
+	// LogQueryArgs causes argument values to be included in the entries passed to QueryLogger. Arguments may contain
+	// sensitive data so only their number is included by default.
+	LogQueryArgs bool
+
+	// CircuitBreaker configures Acquire to fail fast with ErrCircuitOpen after repeated connection failures. See
+	// CircuitBreaker for details. It is disabled by default.
+	CircuitBreaker CircuitBreaker
+
+	// MaxConnLifetime is the duration since creation after which a connection will be automatically closed. An idle
+	// connection is closed by the health check. An acquired connection is closed when it is released. The health check
+	// creates replacements for acquired connections past their lifetime ahead of time so the pool stays at MinConns
+	// when they are closed.
 	MaxConnLifetime time.Duration
 
 	// MaxConnLifetimeJitter is the duration after MaxConnLifetime to randomly decide to close a connection.
@@ -150,12 +234,47 @@ func (c *Config) Copy() *Config {
 	newConfig := new(Config)
 	*newConfig = *c
 	newConfig.ConnConfig = c.ConnConfig.Copy()
+	if c.PreparedStatements != nil {
+		newConfig.PreparedStatements = make(map[string]string, len(c.PreparedStatements))
+		for name, sql := range c.PreparedStatements {
+			newConfig.PreparedStatements[name] = sql
+		}
+	}
 	return newConfig
 }
 
 // ConnString returns the connection string as parsed by pgxpool.ParseConfig into pgxpool.Config.
 func (c *Config) ConnString() string { return c.ConnConfig.ConnString() }
 
+// QueryIdempotent may be passed as an argument to Pool.Exec or Pool.Query to mark the query as idempotent. When a
+// RetryPolicy is configured, an idempotent query may be retried even if it may have already been sent to the server.
+// It is also accepted by Pool.QueryRow, but as QueryRow reports query errors from Row.Scan only failures to acquire a
+// connection are retried. It is the same type as pgx.QueryIdempotent so it is ignored by connections acquired from
+// the pool.
+type QueryIdempotent = pgx.QueryIdempotent
+
+// extractQueryIdempotent removes any QueryIdempotent markers from args.
+func extractQueryIdempotent(args []any) ([]any, bool) {
+	idempotent := false
+	for _, arg := range args {
+		if _, ok := arg.(QueryIdempotent); ok {
+			idempotent = true
+			break
+		}
+	}
+	if !idempotent {
+		return args, false
+	}
+
+	filtered := make([]any, 0, len(args)-1)
+	for _, arg := range args {
+		if _, ok := arg.(QueryIdempotent); !ok {
+			filtered = append(filtered, arg)
+		}
+	}
+	return filtered, true
+}
+
 // New creates a new Pool. See ParseConfig for information on connString format.
 func New(ctx context.Context, connString string) (*Pool, error) {
 	config, err := ParseConfig(connString)
@@ -174,12 +293,22 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 		panic("config must be created by ParseConfig")
 	}
 
+	if config.PgBouncerMode && len(config.PreparedStatements) > 0 {
+		return nil, errors.New("PreparedStatements cannot be used with PgBouncerMode")
+	}
+
 	p := &Pool{
 		config:                config,
 		beforeConnect:         config.BeforeConnect,
 		afterConnect:          config.AfterConnect,
 		beforeAcquire:         config.BeforeAcquire,
 		afterRelease:          config.AfterRelease,
+		retryPolicy:           config.RetryPolicy,
+		defaultQueryTimeout:   config.DefaultQueryTimeout,
+		slowQueryThreshold:    config.SlowQueryThreshold,
+		onSlowQuery:           config.OnSlowQuery,
+		queryLogger:           config.QueryLogger,
+		logQueryArgs:          config.LogQueryArgs,
 		minConns:              config.MinConns,
 		maxConns:              config.MaxConns,
 		maxConnLifetime:       config.MaxConnLifetime,
@@ -187,6 +316,9 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 		maxConnIdleTime:       config.MaxConnIdleTime,
 		healthCheckPeriod:     config.HealthCheckPeriod,
 		healthCheckChan:       make(chan struct{}, 1),
+		circuitBreaker:        circuitBreakerState{config: config.CircuitBreaker},
+		hostStats:             make(map[string]*HostStat),
+		acquiredConns:         make(map[*connResource]struct{}),
 		closeChan:             make(chan struct{}),
 	}
 
@@ -194,32 +326,11 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 	p.p, err = puddle.NewPool(
 		&puddle.Config[*connResource]{
 			Constructor: func(ctx context.Context) (*connResource, error) {
-				connConfig := p.config.ConnConfig.Copy()
-
-				// Connection will continue in background even if Acquire is canceled. Ensure that a connect won't hang forever.
-				if connConfig.ConnectTimeout <= 0 {
-					connConfig.ConnectTimeout = 2 * time.Minute
-				}
-
-				if p.beforeConnect != nil {
-					if err := p.beforeConnect(ctx, connConfig); err != nil {
-						return nil, err
-					}
-				}
-
-				conn, err := pgx.ConnectConfig(ctx, connConfig)
+				conn, err := p.connect(ctx)
 				if err != nil {
 					return nil, err
 				}
 
-				if p.afterConnect != nil {
-					err = p.afterConnect(ctx, conn)
-					if err != nil {
-						conn.Close(ctx)
-						return nil, err
-					}
-				}
-
 				jitterSecs := rand.Float64() * config.MaxConnLifetimeJitter.Seconds()
 				maxAgeTime := time.Now().Add(config.MaxConnLifetime).Add(time.Duration(jitterSecs) * time.Second)
 
@@ -229,11 +340,15 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 					poolRows:   make([]poolRow, 64),
 					poolRowss:  make([]poolRows, 64),
 					maxAgeTime: maxAgeTime,
+					host:       conn.PgConn().Conn().RemoteAddr().String(),
 				}
+				p.hostConnAdded(cr.host, 1)
 
 				return cr, nil
 			},
 			Destructor: func(value *connResource) {
+				p.hostConnAdded(value.host, -1)
+
 				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 				conn := value.conn
 				conn.Close(ctx)
@@ -258,6 +373,56 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 	return p, nil
 }
 
+// connect establishes a new connection using the pool's connection settings and hooks. It is not added to the pool.
+func (p *Pool) connect(ctx context.Context) (*pgx.Conn, error) {
+	connConfig, err := p.prepareConnConfig(ctx, p.config.ConnConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil && p.config.OnAuthFailure != nil && isAuthError(err) {
+		retryConfig, refreshErr := p.config.OnAuthFailure(ctx)
+		if refreshErr != nil {
+			return nil, fmt.Errorf("credential refresh after authentication failure failed: %w", refreshErr)
+		}
+		if retryConfig == nil {
+			return nil, fmt.Errorf("credential refresh after authentication failure returned a nil config: %w", err)
+		}
+
+		retryConfig, err = p.prepareConnConfig(ctx, retryConfig)
+		if err != nil {
+			return nil, err
+		}
+		conn, err = pgx.ConnectConfig(ctx, retryConfig)
+	}
+	// A connect that failed because ctx is done says nothing about whether the database is reachable.
+	if err == nil || ctx.Err() == nil {
+		p.circuitBreaker.recordConnect(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.afterConnect != nil {
+		err = p.afterConnect(ctx, conn)
+		if err != nil {
+			conn.Close(ctx)
+			return nil, err
+		}
+	}
+
+	if len(p.config.PreparedStatements) > 0 {
+		err = conn.PrepareAll(ctx, p.config.PreparedStatements)
+		if err != nil {
+			conn.Close(ctx)
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
 // ParseConfig builds a Config from connString. It parses connString with the same behavior as pgx.ParseConfig with the
 // addition of the following variables:
 //
@@ -368,6 +533,38 @@ func (p *Pool) Close() {
 	})
 }
 
+// prepareConnConfig returns a copy of connConfig with the pool's settings applied and BeforeConnect called on it.
+func (p *Pool) prepareConnConfig(ctx context.Context, connConfig *pgx.ConnConfig) (*pgx.ConnConfig, error) {
+	connConfig = connConfig.Copy()
+
+	// Connection will continue in background even if Acquire is canceled. Ensure that a connect won't hang forever.
+	if connConfig.ConnectTimeout <= 0 {
+		connConfig.ConnectTimeout = 2 * time.Minute
+	}
+
+	if p.config.PgBouncerMode {
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+		connConfig.StatementCacheCapacity = 0
+	}
+
+	if p.beforeConnect != nil {
+		if err := p.beforeConnect(ctx, connConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return connConfig, nil
+}
+
+// isAuthError reports whether err is caused by the server rejecting the credentials.
+func isAuthError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "28P01" || pgErr.Code == "28000"
+	}
+	return false
+}
+
 func (p *Pool) isExpired(res *puddle.Resource[*connResource]) bool {
 	return time.Now().After(res.Value().maxAgeTime)
 }
@@ -452,7 +649,15 @@ func (p *Pool) checkMinConns() error {
 	// TotalConns can include ones that are being destroyed but we should have
 	// sleep(500ms) around all of the destroys to help prevent that from throwing
 	// off this check
-	toCreate := p.minConns - p.Stat().TotalConns()
+	totalConns := p.Stat().TotalConns()
+
+	// Acquired connections that are past their lifetime are destroyed when they are
+	// released. Replace them now so the pool does not drop below minConns when
+	// that happens, but do not go over maxConns.
+	toCreate := p.minConns - totalConns + p.expiredAcquiredConns()
+	if toCreate > p.maxConns-totalConns {
+		toCreate = p.maxConns - totalConns
+	}
 	if toCreate > 0 {
 		return p.createIdleResources(context.Background(), int(toCreate))
 	}
@@ -485,8 +690,20 @@ func (p *Pool) createIdleResources(parentCtx context.Context, targetResources in
 	return firstError
 }
 
-// Acquire returns a connection (*Conn) from the Pool
-func (p *Pool) Acquire(ctx context.Context) (*Conn, error) {
+// Acquire returns a connection (*Conn) from the Pool. If the circuit breaker is open it returns ErrCircuitOpen.
+//
+// If ctx is done while a new connection is being established, Acquire returns immediately but establishing the
+// connection continues. When it succeeds the connection is added to the pool as idle rather than destroyed, so the
+// connect round trip is not wasted. Such acquires are counted by Stat.CanceledAcquireCount.
+func (p *Pool) Acquire(ctx context.Context) (c *Conn, err error) {
+	probe, err := p.circuitBreaker.beginAcquire()
+	if err != nil {
+		return nil, err
+	}
+	if probe {
+		defer p.circuitBreaker.endProbe()
+	}
+
 	for {
 		res, err := p.p.Acquire(ctx)
 		if err != nil {
@@ -498,6 +715,7 @@ func (p *Pool) Acquire(ctx context.Context) (*Conn, error) {
 		if res.IdleDuration() > time.Second {
 			err := cr.conn.PgConn().CheckConn()
 			if err != nil {
+				atomic.AddInt64(&p.healthCheckDestroyCount, 1)
 				res.Destroy()
 				continue
 			}
@@ -511,9 +729,28 @@ func (p *Pool) Acquire(ctx context.Context) (*Conn, error) {
 	}
 }
 
+// AcquireWithTimeout is like Acquire but fails with ErrAcquireTimeout if a connection cannot be acquired within
+// timeout. This allows failing fast when the pool is saturated while still using a longer deadline on ctx for the
+// queries run on the acquired *Conn. The timeout is counted by the CanceledAcquireCount pool statistic. If ctx is done
+// before the timeout elapses the error from ctx is returned instead.
+func (p *Pool) AcquireWithTimeout(ctx context.Context, timeout time.Duration) (*Conn, error) {
+	acquireCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c, err := p.Acquire(acquireCtx)
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %v", ErrAcquireTimeout, timeout)
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
 // AcquireFunc acquires a *Conn and calls f with that *Conn. ctx will only affect the Acquire. It has no effect on the
 // call of f. The return value is either an error acquiring the *Conn or the return value of f. The *Conn is
-// automatically released after the call of f.
+// automatically released after the call of f, even if f panics.
 func (p *Pool) AcquireFunc(ctx context.Context, f func(*Conn) error) error {
 	conn, err := p.Acquire(ctx)
 	if err != nil {
@@ -524,8 +761,35 @@ func (p *Pool) AcquireFunc(ctx context.Context, f func(*Conn) error) error {
 	return f(conn)
 }
 
+// AcquireNew establishes a new connection, calls f with it, and closes it. It never uses an existing connection and
+// does not touch idle connections, which makes it useful for self-tests at startup that must prove new connections can
+// be made. The connection is made with the same settings and hooks as pooled connections but is not added to the pool
+// and is not included in Stat. It counts towards MaxConns when it is made: if the pool and other connections made by
+// AcquireNew already have MaxConns connections ErrMaxConnsReached is returned. The pool may still grow to MaxConns while
+// it is open. The return value is either an error establishing the connection or the return value of f.
+func (p *Pool) AcquireNew(ctx context.Context, f func(*pgx.Conn) error) error {
+	n := atomic.AddInt32(&p.acquireNewConns, 1)
+	defer atomic.AddInt32(&p.acquireNewConns, -1)
+	if p.p.Stat().TotalResources()+n > p.maxConns {
+		return ErrMaxConnsReached
+	}
+
+	conn, err := p.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		conn.Close(closeCtx)
+	}()
+
+	return f(conn)
+}
+
 // AcquireAllIdle atomically acquires all currently idle connections. Its intended use is for health check and
-// keep-alive functionality. It does not update pool statistics.
+// keep-alive functionality. It does not update pool statistics. Connections that are currently acquired are not
+// returned. Each returned connection must be released by the caller.
 func (p *Pool) AcquireAllIdle(ctx context.Context) []*Conn {
 	resources := p.p.AcquireAllIdle()
 	conns := make([]*Conn, 0, len(resources))
@@ -544,22 +808,142 @@ func (p *Pool) AcquireAllIdle(ctx context.Context) []*Conn {
 // Reset closes all connections, but leaves the pool open. It is intended for use when an error is detected that would
 // disrupt all connections (such as a network interruption or a server state change).
 //
-// It is safe to reset a pool while connections are checked out. Those connections will be closed when they are returned
-// to the pool.
+// It is safe to reset a pool while connections are checked out and concurrently with Acquire. Those connections will
+// be closed when they are returned to the pool. New connections are established lazily as they are acquired, so Reset
+// can be used to apply rotated credentials or other settings changed by BeforeConnect.
 func (p *Pool) Reset() {
 	p.p.Reset()
 }
 
-// Config returns a copy of config that was used to initialize this pool.
+// HandleFailover notifies the pool that the server it connects to has failed over, e.g. in response to an out-of-band
+// signal from a high availability system. Idle connections are closed immediately and checked-out connections are
+// closed when they are released, so the pool converges onto the new primary without waiting for each connection to
+// fail individually. New connections up to MinConns are established before HandleFailover returns or ctx is canceled.
+// Errors establishing them are ignored; the background health check will continue trying.
+func (p *Pool) HandleFailover(ctx context.Context) {
+	p.p.Reset()
+
+	// Checked-out connections will be closed on release so they do not count towards MinConns.
+	stat := p.Stat()
+	toCreate := p.minConns - stat.IdleConns()
+	if available := p.maxConns - stat.TotalConns(); toCreate > available {
+		toCreate = available
+	}
+	if toCreate > 0 {
+		p.createIdleResources(ctx, int(toCreate))
+	}
+}
+
+// Config returns a copy of config that was used to initialize this pool. It includes the default values applied by
+// ParseConfig for settings that were not in the connection string, so it can be used to log or check the settings the
+// pool is actually using. Modifying the returned config does not change the settings of the pool.
 func (p *Pool) Config() *Config { return p.config.Copy() }
 
+// QueryLogEntry describes a call passed to Config.QueryLogger.
+type QueryLogEntry struct {
+	// SQL is the SQL of the query. For a batch it is the SQL of all queued queries separated by semicolons. For
+	// CopyFrom it is the COPY statement.
+	SQL string
+
+	// Args are the argument values. It is nil unless Config.LogQueryArgs is true. For a batch it holds the arguments of
+	// all queued queries.
+	Args []any
+
+	// ArgsCount is the number of arguments.
+	ArgsCount int
+
+	Duration time.Duration
+	Err      error
+}
+
+// tracksQueries returns true if the duration of queries must be measured for OnSlowQuery or QueryLogger.
+func (p *Pool) tracksQueries() bool {
+	return p.onSlowQuery != nil || p.queryLogger != nil
+}
+
+// checkSlowQuery calls OnSlowQuery if duration exceeds SlowQueryThreshold.
+func (p *Pool) checkSlowQuery(ctx context.Context, sql string, duration time.Duration, args []any) {
+	if p.onSlowQuery != nil && duration > p.slowQueryThreshold {
+		p.onSlowQuery(ctx, sql, duration, args)
+	}
+}
+
+// logQuery calls QueryLogger if it is set.
+func (p *Pool) logQuery(ctx context.Context, sql string, duration time.Duration, args []any, err error) {
+	if p.queryLogger == nil {
+		return
+	}
+
+	entry := QueryLogEntry{SQL: sql, ArgsCount: len(args), Duration: duration, Err: err}
+	if p.logQueryArgs {
+		entry.Args = args
+	}
+	p.queryLogger(ctx, entry)
+}
+
+// HostStats returns a snapshot of the connections in the pool grouped by the network address of the server they are
+// connected to. Connections that are being checked by the background health check are counted as idle.
+func (p *Pool) HostStats() map[string]HostStat {
+	p.hostStatsMux.Lock()
+	defer p.hostStatsMux.Unlock()
+
+	stats := make(map[string]HostStat, len(p.hostStats))
+	for host, hs := range p.hostStats {
+		stats[host] = *hs
+	}
+	return stats
+}
+
+func (p *Pool) hostConnAdded(host string, delta int32) {
+	p.hostStatsMux.Lock()
+	hs, ok := p.hostStats[host]
+	if !ok {
+		hs = &HostStat{}
+		p.hostStats[host] = hs
+	}
+	hs.totalConns += delta
+	p.hostStatsMux.Unlock()
+}
+
+func (p *Pool) connAcquired(cr *connResource, delta int32) {
+	p.hostStatsMux.Lock()
+	if hs, ok := p.hostStats[cr.host]; ok {
+		hs.acquiredConns += delta
+	}
+	if delta > 0 {
+		p.acquiredConns[cr] = struct{}{}
+	} else {
+		delete(p.acquiredConns, cr)
+	}
+	p.hostStatsMux.Unlock()
+}
+
+// expiredAcquiredConns returns the number of acquired connections that are past their lifetime. They will be destroyed
+// when they are released.
+func (p *Pool) expiredAcquiredConns() int32 {
+	now := time.Now()
+
+	p.hostStatsMux.Lock()
+	defer p.hostStatsMux.Unlock()
+
+	var n int32
+	for cr := range p.acquiredConns {
+		if now.After(cr.maxAgeTime) {
+			n++
+		}
+	}
+	return n
+}
+
 // Stat returns a pgxpool.Stat struct with a snapshot of Pool statistics.
 func (p *Pool) Stat() *Stat {
 	return &Stat{
-		s:                    p.p.Stat(),
-		newConnsCount:        atomic.LoadInt64(&p.newConnsCount),
-		lifetimeDestroyCount: atomic.LoadInt64(&p.lifetimeDestroyCount),
-		idleDestroyCount:     atomic.LoadInt64(&p.idleDestroyCount),
+		s:                       p.p.Stat(),
+		newConnsCount:           atomic.LoadInt64(&p.newConnsCount),
+		lifetimeDestroyCount:    atomic.LoadInt64(&p.lifetimeDestroyCount),
+		idleDestroyCount:        atomic.LoadInt64(&p.idleDestroyCount),
+		healthCheckDestroyCount: atomic.LoadInt64(&p.healthCheckDestroyCount),
+		brokenConnDestroyCount:  atomic.LoadInt64(&p.brokenConnDestroyCount),
 	}
 }
 
@@ -568,13 +952,28 @@ func (p *Pool) Stat() *Stat {
 // Arguments should be referenced positionally from the SQL string as $1, $2, etc.
 // The acquired connection is returned to the pool when the Exec function returns.
 func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	c, err := p.Acquire(ctx)
-	if err != nil {
-		return pgconn.CommandTag{}, err
-	}
-	defer c.Release()
+	arguments, idempotent := extractQueryIdempotent(arguments)
+
+	ctx, cancel := p.withDefaultQueryTimeout(ctx)
+	defer cancel()
 
-	return c.Exec(ctx, sql, arguments...)
+	for attempt := 1; ; attempt++ {
+		c, err := p.Acquire(ctx)
+		if err != nil {
+			if p.shouldRetry(ctx, err, attempt, true) {
+				continue
+			}
+			return pgconn.CommandTag{}, err
+		}
+
+		commandTag, err := c.Exec(ctx, sql, arguments...)
+		c.Release()
+		if err != nil && p.shouldRetry(ctx, err, attempt, idempotent) {
+			continue
+		}
+
+		return commandTag, err
+	}
 }
 
 // Query acquires a connection and executes a query that returns pgx.Rows.
@@ -588,18 +987,59 @@ func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 // QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
 // needed. See the documentation for those types for details.
 func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	c, err := p.Acquire(ctx)
-	if err != nil {
-		return errRows{err: err}, err
+	args, idempotent := extractQueryIdempotent(args)
+
+	ctx, cancel := p.withDefaultQueryTimeout(ctx)
+
+	for attempt := 1; ; attempt++ {
+		c, err := p.Acquire(ctx)
+		if err != nil {
+			if p.shouldRetry(ctx, err, attempt, true) {
+				continue
+			}
+			cancel()
+			return errRows{err: err}, err
+		}
+
+		rows, err := c.Query(ctx, sql, args...)
+		if err != nil {
+			c.Release()
+			if p.shouldRetry(ctx, err, attempt, idempotent) {
+				continue
+			}
+			cancel()
+			return errRows{err: err}, err
+		}
+
+		pr := c.getPoolRows(rows)
+		pr.cancel = cancel
+		return pr, nil
 	}
+}
 
-	rows, err := c.Query(ctx, sql, args...)
-	if err != nil {
-		c.Release()
-		return errRows{err: err}, err
+// withDefaultQueryTimeout returns ctx with DefaultQueryTimeout applied if it is set and ctx has no deadline.
+func (p *Pool) withDefaultQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.defaultQueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, p.defaultQueryTimeout)
+}
+
+// shouldRetry returns true if a query that failed with err on attempt should be retried according to the configured
+// RetryPolicy.
+func (p *Pool) shouldRetry(ctx context.Context, err error, attempt int, idempotent bool) bool {
+	if p.retryPolicy == nil || ctx.Err() != nil {
+		return false
+	}
+
+	if !idempotent && !pgconn.SafeToRetry(err) {
+		return false
 	}
 
-	return c.getPoolRows(rows), nil
+	return p.retryPolicy(err, attempt)
 }
 
 // QueryRow acquires a connection and executes a query that is expected
@@ -615,13 +1055,28 @@ func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, er
 // QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
 // needed. See the documentation for those types for details.
 func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	c, err := p.Acquire(ctx)
-	if err != nil {
+	args, _ = extractQueryIdempotent(args)
+
+	ctx, cancel := p.withDefaultQueryTimeout(ctx)
+
+	var c *Conn
+	for attempt := 1; ; attempt++ {
+		var err error
+		c, err = p.Acquire(ctx)
+		if err == nil {
+			break
+		}
+		if p.shouldRetry(ctx, err, attempt, true) {
+			continue
+		}
+		cancel()
 		return errRow{err: err}
 	}
 
 	row := c.QueryRow(ctx, sql, args...)
-	return c.getPoolRow(row)
+	pr := c.getPoolRow(row)
+	pr.cancel = cancel
+	return pr
 }
 
 func (p *Pool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
@@ -661,6 +1116,20 @@ func (p *Pool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, er
 	return &Tx{t: t, c: c}, nil
 }
 
+// AcquireSnapshot acquires a connection from the Pool and begins a SERIALIZABLE READ ONLY DEFERRABLE transaction. All
+// queries in the transaction see a single consistent snapshot of the database. The begin may block until a safe
+// snapshot is available, but the transaction never blocks writers and cannot fail with a serialization error. This is
+// ideal for long-running reports and backups.
+//
+// Commit or Rollback must be called on the returned transaction to release the connection.
+func (p *Pool) AcquireSnapshot(ctx context.Context) (pgx.Tx, error) {
+	return p.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.Serializable,
+		AccessMode:     pgx.ReadOnly,
+		DeferrableMode: pgx.Deferrable,
+	})
+}
+
 func (p *Pool) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
 	c, err := p.Acquire(ctx)
 	if err != nil {
@@ -668,11 +1137,18 @@ func (p *Pool) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNam
 	}
 	defer c.Release()
 
-	return c.Conn().CopyFrom(ctx, tableName, columnNames, rowSrc)
+	return c.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+// CopyFromRows is shorthand for CopyFrom with pgx.CopyFromRows(rows).
+func (p *Pool) CopyFromRows(ctx context.Context, tableName pgx.Identifier, columnNames []string, rows [][]any) (int64, error) {
+	return p.CopyFrom(ctx, tableName, columnNames, pgx.CopyFromRows(rows))
 }
 
 // Ping acquires a connection from the Pool and executes an empty sql statement against it.
 // If the sql returns without error, the database Ping is considered successful, otherwise, the error is returned.
+// The connection is released before Ping returns. ctx bounds both acquiring the connection, which may require
+// establishing a new one, and executing the statement. This makes Ping suitable for a health check endpoint.
 func (p *Pool) Ping(ctx context.Context) error {
 	c, err := p.Acquire(ctx)
 	if err != nil {
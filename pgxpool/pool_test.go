@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/assert"
@@ -47,6 +50,27 @@ func TestParseConfigExtractsPoolArguments(t *testing.T) {
 	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_min_conns")
 }
 
+func TestPoolConfigIncludesDefaults(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE") + " pool_max_conns=7 pool_max_conn_idle_time=5m")
+	require.NoError(t, err)
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	poolConfig := pool.Config()
+	assert.EqualValues(t, 7, poolConfig.MaxConns)
+	assert.Equal(t, 5*time.Minute, poolConfig.MaxConnIdleTime)
+	assert.EqualValues(t, 0, poolConfig.MinConns)
+	assert.Equal(t, time.Hour, poolConfig.MaxConnLifetime)
+	assert.Equal(t, time.Minute, poolConfig.HealthCheckPeriod)
+
+	poolConfig.MaxConns = 1
+	assert.EqualValues(t, 7, pool.Config().MaxConns)
+	assert.EqualValues(t, 7, pool.Stat().MaxConns())
+}
+
 func TestConstructorIgnoresContext(t *testing.T) {
 	t.Parallel()
 
@@ -141,6 +165,35 @@ func TestPoolAcquireAndConnHijack(t *testing.T) {
 	require.Equal(t, int32(1), n)
 }
 
+func TestPoolPing(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	err = pool.Ping(context.Background())
+	require.NoError(t, err)
+	waitForReleaseToComplete()
+	require.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
+
+func TestPoolPingUnreachableServer(t *testing.T) {
+	t.Parallel()
+
+	// Nothing listens on port 1 so connecting fails immediately.
+	pool, err := pgxpool.New(context.Background(), "host=127.0.0.1 port=1 connect_timeout=5")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = pool.Ping(ctx)
+	require.Error(t, err)
+	require.EqualValues(t, 0, pool.Stat().TotalConns())
+}
+
 func TestPoolAcquireChecksIdleConns(t *testing.T) {
 	t.Parallel()
 
@@ -207,6 +260,79 @@ func TestPoolAcquireFunc(t *testing.T) {
 	require.EqualValues(t, 1, n)
 }
 
+func TestPoolAcquireWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.AcquireWithTimeout(context.Background(), time.Second)
+	require.NoError(t, err)
+
+	canceledAcquireCount := pool.Stat().CanceledAcquireCount()
+
+	start := time.Now()
+	c2, err := pool.AcquireWithTimeout(context.Background(), 50*time.Millisecond)
+	require.ErrorIs(t, err, pgxpool.ErrAcquireTimeout)
+	require.Nil(t, c2)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, canceledAcquireCount+1, pool.Stat().CanceledAcquireCount())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = pool.AcquireWithTimeout(ctx, time.Second)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, pgxpool.ErrAcquireTimeout)
+
+	c.Release()
+
+	c, err = pool.AcquireWithTimeout(context.Background(), time.Second)
+	require.NoError(t, err)
+	c.Release()
+}
+
+func TestPoolAcquireCanceledWhileConnectingRetainsConn(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+	var connectCount int32
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		atomic.AddInt32(&connectCount, 1)
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The connection finishes establishing after the acquire was canceled and is kept as idle.
+	require.Eventually(t, func() bool { return pool.Stat().IdleConns() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	stats := pool.Stat()
+	assert.EqualValues(t, 1, stats.TotalConns())
+	assert.EqualValues(t, 1, stats.CanceledAcquireCount())
+
+	// The next acquire reuses it.
+	c, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	c.Release()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&connectCount))
+}
+
 func TestPoolAcquireFuncReturnsFnError(t *testing.T) {
 	t.Parallel()
 
@@ -220,6 +346,22 @@ func TestPoolAcquireFuncReturnsFnError(t *testing.T) {
 	require.EqualError(t, err, "some error")
 }
 
+func TestPoolAcquireFuncReleasesOnPanic(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.PanicsWithValue(t, "some panic", func() {
+		pool.AcquireFunc(context.Background(), func(c *pgxpool.Conn) error {
+			panic("some panic")
+		})
+	})
+
+	require.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
+
 func TestPoolBeforeConnect(t *testing.T) {
 	t.Parallel()
 
@@ -262,6 +404,141 @@ func TestPoolAfterConnect(t *testing.T) {
 	assert.EqualValues(t, 1, n)
 }
 
+func TestPoolPreparedStatements(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+	config.PreparedStatements = map[string]string{"ps1": "select $1::int4 + 1"}
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		c, err := db.Acquire(context.Background())
+		require.NoError(t, err)
+
+		var n int32
+		err = c.QueryRow(context.Background(), "ps1", 41).Scan(&n)
+		require.NoError(t, err)
+		assert.EqualValues(t, 42, n)
+
+		// Replace the connection to ensure the statement is prepared on the new connection.
+		c.Hijack().Close(context.Background())
+	}
+
+	config = config.Copy()
+	config.PreparedStatements = map[string]string{
+		"good": "select 1",
+		"bad":  "select * from pgxpool_missing_table",
+	}
+	db2, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db2.Close()
+
+	_, err = db2.Acquire(context.Background())
+	require.ErrorContains(t, err, "failed to prepare statement bad")
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "42P01", pgErr.Code)
+}
+
+func TestPoolPgBouncerMode(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.PgBouncerMode = true
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	defer c.Release()
+
+	assert.Equal(t, pgx.QueryExecModeCacheDescribe, c.Conn().Config().DefaultQueryExecMode)
+
+	var n int32
+	err = c.QueryRow(context.Background(), "select $1::int4 + 1", 41).Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, n)
+
+	var preparedCount int
+	err = c.QueryRow(context.Background(), "select count(*) from pg_prepared_statements").Scan(&preparedCount)
+	require.NoError(t, err)
+	assert.Equal(t, 0, preparedCount)
+
+	config = config.Copy()
+	config.PreparedStatements = map[string]string{"ps1": "select 1"}
+	_, err = pgxpool.NewWithConfig(context.Background(), config)
+	require.Error(t, err)
+}
+
+func TestPoolOnAuthFailure(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	validConnConfig := config.ConnConfig.Copy()
+
+	// A role that does not exist fails with SQLSTATE 28000.
+	config.ConnConfig.User = "pgxpool_on_auth_failure_missing_role"
+
+	var onAuthFailureCalls int32
+	config.OnAuthFailure = func(ctx context.Context) (*pgx.ConnConfig, error) {
+		atomic.AddInt32(&onAuthFailureCalls, 1)
+		return validConnConfig, nil
+	}
+
+	// BeforeConnect is called for the first attempt and for the retry.
+	var beforeConnectUsers []string
+	var beforeConnectMux sync.Mutex
+	config.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+		beforeConnectMux.Lock()
+		beforeConnectUsers = append(beforeConnectUsers, cc.User)
+		beforeConnectMux.Unlock()
+		return nil
+	}
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	c.Release()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&onAuthFailureCalls))
+	beforeConnectMux.Lock()
+	assert.Equal(t, []string{"pgxpool_on_auth_failure_missing_role", validConnConfig.User}, beforeConnectUsers)
+	beforeConnectMux.Unlock()
+
+	config = config.Copy()
+	config.OnAuthFailure = func(ctx context.Context) (*pgx.ConnConfig, error) {
+		return nil, errors.New("refresh failed")
+	}
+	db2, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db2.Close()
+
+	_, err = db2.Acquire(context.Background())
+	require.ErrorContains(t, err, "refresh failed")
+
+	config = config.Copy()
+	config.OnAuthFailure = func(ctx context.Context) (*pgx.ConnConfig, error) {
+		return nil, nil
+	}
+	db3, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db3.Close()
+
+	_, err = db3.Acquire(context.Background())
+	require.ErrorContains(t, err, "returned a nil config")
+}
+
 func TestPoolBeforeAcquire(t *testing.T) {
 	t.Parallel()
 
@@ -303,6 +580,31 @@ func TestPoolBeforeAcquire(t *testing.T) {
 	assert.EqualValues(t, 12, acquireAttempts)
 }
 
+func TestPoolBeforeAcquireRespectsContext(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	var acquireAttempts int64
+	config.BeforeAcquire = func(ctx context.Context, c *pgx.Conn) bool {
+		atomic.AddInt64(&acquireAttempts, 1)
+		return false
+	}
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	c, err := db.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Nil(t, c)
+	require.Greater(t, atomic.LoadInt64(&acquireAttempts), int64(0))
+}
+
 func TestPoolAfterRelease(t *testing.T) {
 	t.Parallel()
 
@@ -347,6 +649,33 @@ func TestPoolAfterRelease(t *testing.T) {
 	assert.EqualValues(t, 5, len(connPIDs))
 }
 
+func TestPoolHostStats(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c1, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	c2, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	c2.Release()
+
+	host := c1.Conn().PgConn().Conn().RemoteAddr().String()
+	stats := pool.HostStats()
+	require.Len(t, stats, 1)
+	require.EqualValues(t, 2, stats[host].TotalConns())
+	require.EqualValues(t, 1, stats[host].AcquiredConns())
+	require.EqualValues(t, 1, stats[host].IdleConns())
+
+	c1.Hijack().Close(context.Background())
+
+	stats = pool.HostStats()
+	require.EqualValues(t, 1, stats[host].TotalConns())
+	require.EqualValues(t, 0, stats[host].AcquiredConns())
+}
+
 func TestPoolAcquireAllIdle(t *testing.T) {
 	t.Parallel()
 
@@ -373,6 +702,21 @@ func TestPoolAcquireAllIdle(t *testing.T) {
 	for _, c := range conns {
 		c.Release()
 	}
+	waitForReleaseToComplete()
+
+	busy, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	defer busy.Release()
+
+	conns = db.AcquireAllIdle(context.Background())
+	assert.Len(t, conns, 2)
+	for _, c := range conns {
+		assert.NotSame(t, busy.Conn(), c.Conn())
+		c.Release()
+	}
+	waitForReleaseToComplete()
+
+	assert.EqualValues(t, 2, db.Stat().IdleConns())
 }
 
 func TestPoolReset(t *testing.T) {
@@ -400,193 +744,728 @@ func TestPoolReset(t *testing.T) {
 	require.EqualValues(t, 0, db.Stat().TotalConns())
 }
 
-func TestConnReleaseChecksMaxConnLifetime(t *testing.T) {
+func TestPoolResetPicksUpNewConnectSettings(t *testing.T) {
 	t.Parallel()
 
 	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
 	require.NoError(t, err)
 
-	config.MaxConnLifetime = 250 * time.Millisecond
+	var applicationName atomic.Value
+	applicationName.Store("pgxpool_reset_before")
+	config.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+		cc.RuntimeParams["application_name"] = applicationName.Load().(string)
+		return nil
+	}
 
 	db, err := pgxpool.NewWithConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer db.Close()
 
-	c, err := db.Acquire(context.Background())
+	getApplicationName := func() string {
+		var name string
+		err := db.QueryRow(context.Background(), "show application_name").Scan(&name)
+		require.NoError(t, err)
+		return name
+	}
+
+	require.Equal(t, "pgxpool_reset_before", getApplicationName())
+
+	inUse, err := db.Acquire(context.Background())
 	require.NoError(t, err)
 
-	time.Sleep(config.MaxConnLifetime)
+	// Rotate the setting. Existing connections keep the old value until the pool is reset.
+	applicationName.Store("pgxpool_reset_after")
+	require.Equal(t, "pgxpool_reset_before", getApplicationName())
 
-	c.Release()
-	waitForReleaseToComplete()
+	db.Reset()
+	require.Equal(t, "pgxpool_reset_after", getApplicationName())
 
-	stats := db.Stat()
-	assert.EqualValues(t, 0, stats.TotalConns())
+	inUsePgConn := inUse.Conn().PgConn()
+	inUse.Release()
+	waitForReleaseToComplete()
+	require.True(t, inUsePgConn.IsClosed())
 }
 
-func TestConnReleaseClosesBusyConn(t *testing.T) {
+func TestPoolHandleFailover(t *testing.T) {
 	t.Parallel()
 
-	db, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MinConns = 2
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer db.Close()
 
 	c, err := db.Acquire(context.Background())
 	require.NoError(t, err)
+	acquiredPgConn := c.Conn().PgConn()
 
-	_, err = c.Query(context.Background(), "select generate_series(1,10)")
+	db.HandleFailover(context.Background())
+	require.GreaterOrEqual(t, db.Stat().IdleConns(), int32(2))
+
+	c.Release()
+	waitForReleaseToComplete()
+	require.True(t, acquiredPgConn.IsClosed())
+
+	err = db.AcquireFunc(context.Background(), func(c *pgxpool.Conn) error {
+		require.NotSame(t, acquiredPgConn, c.Conn().PgConn())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPoolAcquireNew(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 2
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
 	require.NoError(t, err)
+	defer db.Close()
 
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	idlePgConn := c.Conn().PgConn()
 	c.Release()
 	waitForReleaseToComplete()
 
-	// wait for the connection to actually be destroyed
-	for i := 0; i < 1000; i++ {
-		if db.Stat().TotalConns() == 0 {
-			break
-		}
-		time.Sleep(time.Millisecond)
-	}
+	var newPgConn *pgconn.PgConn
+	err = db.AcquireNew(context.Background(), func(conn *pgx.Conn) error {
+		newPgConn = conn.PgConn()
+		require.NotSame(t, idlePgConn, newPgConn)
 
-	stats := db.Stat()
-	assert.EqualValues(t, 0, stats.TotalConns())
+		// The connection is not added to the pool and the idle connection is left idle.
+		stat := db.Stat()
+		require.EqualValues(t, 1, stat.TotalConns())
+		require.EqualValues(t, 0, stat.AcquiredConns())
+		require.EqualValues(t, 1, stat.IdleConns())
+
+		// The connection counts towards MaxConns so another cannot be made.
+		err := db.AcquireNew(context.Background(), func(conn *pgx.Conn) error { return nil })
+		require.ErrorIs(t, err, pgxpool.ErrMaxConnsReached)
+
+		return conn.Ping(context.Background())
+	})
+	require.NoError(t, err)
+	require.True(t, newPgConn.IsClosed())
+	require.False(t, idlePgConn.IsClosed())
+	require.EqualValues(t, 1, db.Stat().TotalConns())
+	require.EqualValues(t, 1, db.Stat().IdleConns())
+
+	c1, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	defer c1.Release()
+	c2, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	defer c2.Release()
+
+	err = db.AcquireNew(context.Background(), func(conn *pgx.Conn) error { return nil })
+	require.ErrorIs(t, err, pgxpool.ErrMaxConnsReached)
 }
 
-func TestPoolBackgroundChecksMaxConnLifetime(t *testing.T) {
+func TestConnReleaseChecksMaxConnLifetime(t *testing.T) {
 	t.Parallel()
 
-	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	config.MaxConnLifetime = 250 * time.Millisecond
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+
+	time.Sleep(config.MaxConnLifetime)
+
+	c.Release()
+	waitForReleaseToComplete()
+
+	stats := db.Stat()
+	assert.EqualValues(t, 0, stats.TotalConns())
+}
+
+func TestPoolBackgroundReplacesAcquiredConnPastMaxConnLifetime(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	config.MinConns = 1
+	config.MaxConnLifetime = 2 * time.Second
+	config.HealthCheckPeriod = 100 * time.Millisecond
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	for i := 0; i < 1000 && db.Stat().TotalConns() < 1; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	require.EqualValues(t, 1, db.Stat().TotalConns())
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	expiredPID := c.Conn().PgConn().PID()
+
+	// The health check creates a replacement after the acquired connection is past its lifetime.
+	time.Sleep(config.MaxConnLifetime)
+	for i := 0; i < 1000 && db.Stat().TotalConns() < 2; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := db.Stat()
+	assert.EqualValues(t, 2, stats.TotalConns())
+	assert.EqualValues(t, 1, stats.IdleConns())
+	assert.EqualValues(t, 0, stats.MaxLifetimeDestroyCount())
+
+	c.Release()
+	waitForReleaseToComplete()
+
+	stats = db.Stat()
+	assert.EqualValues(t, 1, stats.TotalConns())
+	assert.EqualValues(t, 1, stats.MaxLifetimeDestroyCount())
+
+	c, err = db.Acquire(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, expiredPID, c.Conn().PgConn().PID())
+	c.Release()
+}
+
+func TestConnReleaseClosesBusyConn(t *testing.T) {
+	t.Parallel()
+
+	db, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+
+	_, err = c.Query(context.Background(), "select generate_series(1,10)")
+	require.NoError(t, err)
+
+	c.Release()
+	waitForReleaseToComplete()
+
+	// wait for the connection to actually be destroyed
+	for i := 0; i < 1000; i++ {
+		if db.Stat().TotalConns() == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := db.Stat()
+	assert.EqualValues(t, 0, stats.TotalConns())
+}
+
+func TestPoolBackgroundChecksMaxConnLifetime(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	config.MaxConnLifetime = 100 * time.Millisecond
+	config.HealthCheckPeriod = 100 * time.Millisecond
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	c.Release()
+	time.Sleep(config.MaxConnLifetime + 500*time.Millisecond)
+
+	stats := db.Stat()
+	assert.EqualValues(t, 0, stats.TotalConns())
+	assert.EqualValues(t, 0, stats.MaxIdleDestroyCount())
+	assert.EqualValues(t, 1, stats.MaxLifetimeDestroyCount())
+}
+
+func TestPoolBackgroundChecksMaxConnIdleTime(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	config.MaxConnLifetime = 1 * time.Minute
+	config.MaxConnIdleTime = 100 * time.Millisecond
+	config.HealthCheckPeriod = 150 * time.Millisecond
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	c.Release()
+	time.Sleep(config.HealthCheckPeriod)
+
+	for i := 0; i < 1000; i++ {
+		if db.Stat().TotalConns() == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := db.Stat()
+	assert.EqualValues(t, 0, stats.TotalConns())
+	assert.EqualValues(t, 1, stats.MaxIdleDestroyCount())
+	assert.EqualValues(t, 0, stats.MaxLifetimeDestroyCount())
+}
+
+func TestPoolBackgroundChecksMinConns(t *testing.T) {
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	config.HealthCheckPeriod = 100 * time.Millisecond
+	config.MinConns = 2
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	time.Sleep(config.HealthCheckPeriod + 500*time.Millisecond)
+
+	stats := db.Stat()
+	assert.EqualValues(t, 2, stats.TotalConns())
+	assert.EqualValues(t, 0, stats.MaxLifetimeDestroyCount())
+	assert.EqualValues(t, 2, stats.NewConnsCount())
+
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	err = c.Conn().Close(context.Background())
+	require.NoError(t, err)
+	c.Release()
+
+	time.Sleep(config.HealthCheckPeriod + 500*time.Millisecond)
+
+	stats = db.Stat()
+	assert.EqualValues(t, 2, stats.TotalConns())
+	assert.EqualValues(t, 0, stats.MaxIdleDestroyCount())
+	assert.EqualValues(t, 3, stats.NewConnsCount())
+}
+
+func TestPoolStatDestroyCounts(t *testing.T) {
+	t.Parallel()
+
+	db, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A connection that is closed while acquired is destroyed on release.
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	err = c.Conn().Close(context.Background())
+	require.NoError(t, err)
+	c.Release()
+	waitForReleaseToComplete()
+
+	stats := db.Stat()
+	assert.EqualValues(t, 1, stats.BrokenConnDestroyCount())
+	assert.EqualValues(t, 0, stats.HealthCheckDestroyCount())
+
+	// An idle connection that was terminated by the server is destroyed when it fails the check on acquire.
+	c1, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	c2, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	_, err = c2.Exec(context.Background(), "select pg_terminate_backend($1)", c1.Conn().PgConn().PID())
+	require.NoError(t, err)
+	c1.Release()
+	c2.Release()
+	waitForReleaseToComplete()
+
+	// Connections are only checked after they have been idle for more than a second.
+	time.Sleep(1100 * time.Millisecond)
+
+	c1, err = db.Acquire(context.Background())
+	require.NoError(t, err)
+	c2, err = db.Acquire(context.Background())
+	require.NoError(t, err)
+	c1.Release()
+	c2.Release()
+
+	stats = db.Stat()
+	assert.EqualValues(t, 1, stats.HealthCheckDestroyCount())
+}
+
+func TestPoolStatAcquireCounts(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The first acquire waits for the connection to be established.
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	c.Release()
+	waitForReleaseToComplete()
+
+	stats := db.Stat()
+	assert.EqualValues(t, 1, stats.AcquireCount())
+	assert.EqualValues(t, 1, stats.EmptyAcquireCount())
+
+	// Acquires that are served by the idle connection do not wait.
+	for i := 0; i < 5; i++ {
+		c, err := db.Acquire(context.Background())
+		require.NoError(t, err)
+		c.Release()
+		waitForReleaseToComplete()
+	}
+
+	stats = db.Stat()
+	assert.EqualValues(t, 6, stats.AcquireCount())
+	assert.EqualValues(t, 1, stats.EmptyAcquireCount())
+
+	// An acquire while the only connection is busy waits for it to be released.
+	c, err = db.Acquire(context.Background())
+	require.NoError(t, err)
+
+	acquired := make(chan time.Duration)
+	go func() {
+		startTime := time.Now()
+		c, err := db.Acquire(context.Background())
+		if err == nil {
+			c.Release()
+		}
+		acquired <- time.Since(startTime)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	c.Release()
+	waitTime := <-acquired
+	waitForReleaseToComplete()
+
+	stats = db.Stat()
+	assert.EqualValues(t, 8, stats.AcquireCount())
+	assert.EqualValues(t, 2, stats.EmptyAcquireCount())
+	assert.GreaterOrEqual(t, stats.AcquireDuration(), waitTime/2)
+}
+
+func TestPoolExec(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	testExec(t, pool)
+}
+
+func TestPoolQuery(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// Test common usage
+	testQuery(t, pool)
+	waitForReleaseToComplete()
+
+	// Test expected pool behavior
+	rows, err := pool.Query(context.Background(), "select generate_series(1,$1)", 10)
+	require.NoError(t, err)
+
+	stats := pool.Stat()
+	assert.EqualValues(t, 1, stats.AcquiredConns())
+	assert.EqualValues(t, 1, stats.TotalConns())
+
+	rows.Close()
+	assert.NoError(t, rows.Err())
+	waitForReleaseToComplete()
+
+	stats = pool.Stat()
+	assert.EqualValues(t, 0, stats.AcquiredConns())
+	assert.EqualValues(t, 1, stats.TotalConns())
+
+}
+
+func TestPoolDefaultQueryTimeout(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.DefaultQueryTimeout = 100 * time.Millisecond
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// Without a deadline the default timeout cancels the query.
+	startTime := time.Now()
+	_, err = pool.Exec(context.Background(), "select pg_sleep(5)")
+	require.Error(t, err)
+	require.True(t, pgconn.Timeout(err), "expected timeout error, got %v", err)
+	require.Less(t, time.Since(startTime), 4*time.Second)
+
+	rows, err := pool.Query(context.Background(), "select pg_sleep(5)")
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+	}
+	require.True(t, pgconn.Timeout(err), "expected timeout error, got %v", err)
+
+	err = pool.QueryRow(context.Background(), "select pg_sleep(5)").Scan(nil)
+	require.True(t, pgconn.Timeout(err), "expected timeout error, got %v", err)
+
+	// An explicit deadline is used instead of the default timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = pool.Exec(ctx, "select pg_sleep(0.3)")
+	require.NoError(t, err)
+
+	var n int32
+	err = pool.QueryRow(ctx, "select 1 from pg_sleep(0.3)").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	// The default timeout does not affect queries that finish in time. The rows can be read after Query returns.
+	rows, err = pool.Query(context.Background(), "select generate_series(1, 3)")
+	require.NoError(t, err)
+	numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	require.NoError(t, err)
+	require.Equal(t, []int32{1, 2, 3}, numbers)
+}
+
+func TestPoolQueryRow(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	testQueryRow(t, pool)
+	waitForReleaseToComplete()
+
+	stats := pool.Stat()
+	assert.EqualValues(t, 0, stats.AcquiredConns())
+	assert.EqualValues(t, 1, stats.TotalConns())
+}
+
+// https://github.com/jackc/pgx/issues/677
+func TestPoolQueryRowErrNoRows(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	err = pool.QueryRow(context.Background(), "select n from generate_series(1,10) n where n=0").Scan(nil)
+	require.Equal(t, pgx.ErrNoRows, err)
+}
+
+func TestPoolRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	var attempts []int
+	config.RetryPolicy = func(err error, attempt int) bool {
+		attempts = append(attempts, attempt)
+		return attempt < 3
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	require.NoError(t, err)
+	defer pool.Close()
 
-	config.MaxConnLifetime = 100 * time.Millisecond
-	config.HealthCheckPeriod = 100 * time.Millisecond
+	// Server errors are not safe to retry unless the query is marked idempotent.
+	_, err = pool.Exec(context.Background(), "select 1/0")
+	require.Error(t, err)
+	require.Empty(t, attempts)
 
-	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	_, err = pool.Exec(context.Background(), "select 1/0", pgxpool.QueryIdempotent{})
+	require.Error(t, err)
+	require.Equal(t, []int{1, 2, 3}, attempts)
+
+	attempts = nil
+	_, err = pool.Query(context.Background(), "select * from pgxpool_retry_missing_table", pgxpool.QueryIdempotent{})
+	require.Error(t, err)
+	require.Equal(t, []int{1, 2, 3}, attempts)
+
+	rows, err := pool.Query(context.Background(), "select $1::int4", pgxpool.QueryIdempotent{}, 42)
 	require.NoError(t, err)
-	defer db.Close()
+	n, err := pgx.CollectOneRow(rows, pgx.RowTo[int32])
+	require.NoError(t, err)
+	require.EqualValues(t, 42, n)
 
-	c, err := db.Acquire(context.Background())
+	// QueryRow accepts the marker but reports query errors from Scan without retrying.
+	err = pool.QueryRow(context.Background(), "select $1::int4", pgxpool.QueryIdempotent{}, 43).Scan(&n)
 	require.NoError(t, err)
-	c.Release()
-	time.Sleep(config.MaxConnLifetime + 500*time.Millisecond)
+	require.EqualValues(t, 43, n)
 
-	stats := db.Stat()
-	assert.EqualValues(t, 0, stats.TotalConns())
-	assert.EqualValues(t, 0, stats.MaxIdleDestroyCount())
-	assert.EqualValues(t, 1, stats.MaxLifetimeDestroyCount())
+	attempts = nil
+	err = pool.QueryRow(context.Background(), "select 1/0", pgxpool.QueryIdempotent{}).Scan(&n)
+	require.Error(t, err)
+	require.Empty(t, attempts)
 }
 
-func TestPoolBackgroundChecksMaxConnIdleTime(t *testing.T) {
+func TestConnQueryIdempotentIsIgnored(t *testing.T) {
 	t.Parallel()
 
-	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
 	require.NoError(t, err)
+	defer pool.Close()
 
-	config.MaxConnLifetime = 1 * time.Minute
-	config.MaxConnIdleTime = 100 * time.Millisecond
-	config.HealthCheckPeriod = 150 * time.Millisecond
-
-	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	c, err := pool.Acquire(context.Background())
 	require.NoError(t, err)
-	defer db.Close()
+	defer c.Release()
 
-	c, err := db.Acquire(context.Background())
+	_, err = c.Exec(context.Background(), "select $1::int4", pgxpool.QueryIdempotent{}, 42)
 	require.NoError(t, err)
-	c.Release()
-	time.Sleep(config.HealthCheckPeriod)
 
-	for i := 0; i < 1000; i++ {
-		if db.Stat().TotalConns() == 0 {
-			break
-		}
-		time.Sleep(time.Millisecond)
-	}
+	var n int32
+	err = c.QueryRow(context.Background(), "select $1::int4", pgxpool.QueryIdempotent{}, 42).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, n)
 
-	stats := db.Stat()
-	assert.EqualValues(t, 0, stats.TotalConns())
-	assert.EqualValues(t, 1, stats.MaxIdleDestroyCount())
-	assert.EqualValues(t, 0, stats.MaxLifetimeDestroyCount())
+	err = c.Conn().QueryRow(context.Background(), "select $1::int4", pgx.QueryIdempotent{}, 43).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 43, n)
 }
 
-func TestPoolBackgroundChecksMinConns(t *testing.T) {
+func TestPoolOnSlowQuery(t *testing.T) {
+	t.Parallel()
+
 	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
 	require.NoError(t, err)
 
-	config.HealthCheckPeriod = 100 * time.Millisecond
-	config.MinConns = 2
+	var slowSQLs []string
+	config.SlowQueryThreshold = 50 * time.Millisecond
+	config.OnSlowQuery = func(ctx context.Context, sql string, duration time.Duration, args []any) {
+		require.Greater(t, duration, 50*time.Millisecond)
+		slowSQLs = append(slowSQLs, sql)
+	}
 
-	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	require.NoError(t, err)
-	defer db.Close()
-
-	time.Sleep(config.HealthCheckPeriod + 500*time.Millisecond)
+	defer pool.Close()
 
-	stats := db.Stat()
-	assert.EqualValues(t, 2, stats.TotalConns())
-	assert.EqualValues(t, 0, stats.MaxLifetimeDestroyCount())
-	assert.EqualValues(t, 2, stats.NewConnsCount())
+	_, err = pool.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+	require.Empty(t, slowSQLs)
 
-	c, err := db.Acquire(context.Background())
+	_, err = pool.Exec(context.Background(), "select pg_sleep(0.1)")
 	require.NoError(t, err)
-	err = c.Conn().Close(context.Background())
+
+	rows, err := pool.Query(context.Background(), "select n, pg_sleep(0.03) from generate_series(1, 3) n")
 	require.NoError(t, err)
-	c.Release()
+	rows.Close()
+	require.NoError(t, rows.Err())
 
-	time.Sleep(config.HealthCheckPeriod + 500*time.Millisecond)
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	batch.Queue("select pg_sleep(0.1)")
+	err = pool.SendBatch(context.Background(), batch).Close()
+	require.NoError(t, err)
 
-	stats = db.Stat()
-	assert.EqualValues(t, 2, stats.TotalConns())
-	assert.EqualValues(t, 0, stats.MaxIdleDestroyCount())
-	assert.EqualValues(t, 3, stats.NewConnsCount())
+	require.Equal(t, []string{
+		"select pg_sleep(0.1)",
+		"select n, pg_sleep(0.03) from generate_series(1, 3) n",
+		"select 1; select pg_sleep(0.1)",
+	}, slowSQLs)
 }
 
-func TestPoolExec(t *testing.T) {
+func TestPoolQueryLogger(t *testing.T) {
 	t.Parallel()
 
-	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	var entries []pgxpool.QueryLogEntry
+	config.QueryLogger = func(ctx context.Context, entry pgxpool.QueryLogEntry) {
+		entries = append(entries, entry)
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer pool.Close()
 
-	testExec(t, pool)
-}
+	ctx := context.Background()
 
-func TestPoolQuery(t *testing.T) {
-	t.Parallel()
+	_, err = pool.Exec(ctx, "select pg_sleep(0.1), $1::text", "secret")
+	require.NoError(t, err)
 
-	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	rows, err := pool.Query(ctx, "select $1::text, $2::text", "secret", "secret")
 	require.NoError(t, err)
-	defer pool.Close()
+	rows.Close()
+	require.NoError(t, rows.Err())
 
-	// Test common usage
-	testQuery(t, pool)
-	waitForReleaseToComplete()
+	var n int32
+	err = pool.QueryRow(ctx, "select 1/$1::int4", 0).Scan(&n)
+	require.Error(t, err)
 
-	// Test expected pool behavior
-	rows, err := pool.Query(context.Background(), "select generate_series(1,$1)", 10)
+	batch := &pgx.Batch{}
+	batch.Queue("select $1::text", "secret")
+	batch.Queue("select pg_sleep(0.1)")
+	err = pool.SendBatch(ctx, batch).Close()
 	require.NoError(t, err)
 
-	stats := pool.Stat()
-	assert.EqualValues(t, 1, stats.AcquiredConns())
-	assert.EqualValues(t, 1, stats.TotalConns())
+	_, err = pool.Exec(ctx, "create temporary table query_logger_copy (a int4)")
+	require.NoError(t, err)
+	_, err = pool.CopyFrom(ctx, pgx.Identifier{"query_logger_copy"}, []string{"a"}, pgx.CopyFromRows([][]any{{int32(1)}}))
+	require.NoError(t, err)
 
-	rows.Close()
-	assert.NoError(t, rows.Err())
-	waitForReleaseToComplete()
+	// One entry per call with only the number of arguments by default.
+	require.Len(t, entries, 6)
+	require.Equal(t, "select pg_sleep(0.1), $1::text", entries[0].SQL)
+	require.Equal(t, "select $1::text, $2::text", entries[1].SQL)
+	require.Equal(t, "select 1/$1::int4", entries[2].SQL)
+	require.Equal(t, "select $1::text; select pg_sleep(0.1)", entries[3].SQL)
+	require.Equal(t, `copy "query_logger_copy" ("a") from stdin binary`, entries[5].SQL)
+	for i, argsCount := range []int{1, 2, 1, 1, 0, 0} {
+		require.Equal(t, argsCount, entries[i].ArgsCount)
+		require.Nil(t, entries[i].Args)
+	}
 
-	stats = pool.Stat()
-	assert.EqualValues(t, 0, stats.AcquiredConns())
-	assert.EqualValues(t, 1, stats.TotalConns())
+	require.NoError(t, entries[0].Err)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, entries[2].Err, &pgErr)
+	require.Equal(t, "22012", pgErr.Code)
+
+	// The duration covers the server's execution time.
+	require.GreaterOrEqual(t, entries[0].Duration, 100*time.Millisecond)
+	require.GreaterOrEqual(t, entries[3].Duration, 100*time.Millisecond)
 
+	config.LogQueryArgs = true
+	pool, err = pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	entries = nil
+	_, err = pool.Exec(ctx, "select $1::text", "secret")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, []any{"secret"}, entries[0].Args)
+	require.Equal(t, 1, entries[0].ArgsCount)
 }
 
-func TestPoolQueryRow(t *testing.T) {
+func TestPoolSendBatch(t *testing.T) {
 	t.Parallel()
 
 	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
 	require.NoError(t, err)
 	defer pool.Close()
 
-	testQueryRow(t, pool)
+	testSendBatch(t, pool)
 	waitForReleaseToComplete()
 
 	stats := pool.Stat()
@@ -594,31 +1473,30 @@ func TestPoolQueryRow(t *testing.T) {
 	assert.EqualValues(t, 1, stats.TotalConns())
 }
 
-// https://github.com/jackc/pgx/issues/677
-func TestPoolQueryRowErrNoRows(t *testing.T) {
+func TestPoolSendBatchReleasesConnOnError(t *testing.T) {
 	t.Parallel()
 
 	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
 	require.NoError(t, err)
 	defer pool.Close()
 
-	err = pool.QueryRow(context.Background(), "select n from generate_series(1,10) n where n=0").Scan(nil)
-	require.Equal(t, pgx.ErrNoRows, err)
-}
-
-func TestPoolSendBatch(t *testing.T) {
-	t.Parallel()
-
-	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
-	require.NoError(t, err)
-	defer pool.Close()
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	batch.Queue("select 1 1")
 
-	testSendBatch(t, pool)
+	br := pool.SendBatch(context.Background(), batch)
+	err = br.Close()
+	require.Error(t, err)
 	waitForReleaseToComplete()
 
 	stats := pool.Stat()
 	assert.EqualValues(t, 0, stats.AcquiredConns())
-	assert.EqualValues(t, 1, stats.TotalConns())
+
+	// The connection is usable after the failed batch.
+	var n int32
+	err = pool.QueryRow(context.Background(), "select 1").Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
 }
 
 func TestPoolCopyFrom(t *testing.T) {
@@ -667,6 +1545,38 @@ func TestPoolCopyFrom(t *testing.T) {
 	assert.Equal(t, inputRows, outputRows)
 }
 
+func TestPoolCopyFromRows(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Exec(ctx, `drop table if exists poolcopyfromrowstest`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `create table poolcopyfromrowstest(a int4, b text)`)
+	require.NoError(t, err)
+	defer pool.Exec(ctx, `drop table poolcopyfromrowstest`)
+
+	inputRows := [][]any{
+		{int32(1), "abc"},
+		{nil, nil},
+	}
+
+	copyCount, err := pool.CopyFromRows(ctx, pgx.Identifier{"poolcopyfromrowstest"}, []string{"a", "b"}, inputRows)
+	require.NoError(t, err)
+	require.EqualValues(t, len(inputRows), copyCount)
+
+	rows, _ := pool.Query(ctx, "select a, b from poolcopyfromrowstest order by a nulls last")
+	outputRows, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) ([]any, error) { return row.Values() })
+	require.NoError(t, err)
+	require.Equal(t, inputRows, outputRows)
+}
+
 func TestConnReleaseClosesConnInFailedTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -785,6 +1695,41 @@ func TestConnReleaseDestroysClosedConn(t *testing.T) {
 	assert.EqualValues(t, 0, pool.Stat().TotalConns())
 }
 
+func TestConnReleaseDestroysConnAfterUnexpectedMessage(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+
+	_, err = c.Exec(ctx, "create temporary table foo(a int4); copy foo from stdin")
+	require.ErrorIs(t, err, pgconn.ErrUnexpectedMessage)
+	require.True(t, c.Conn().IsClosed())
+
+	c.Release()
+	waitForReleaseToComplete()
+
+	// wait for the connection to actually be destroyed
+	for i := 0; i < 1000; i++ {
+		if pool.Stat().TotalConns() == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.EqualValues(t, 0, pool.Stat().TotalConns())
+
+	var n int32
+	err = pool.QueryRow(ctx, "select 1").Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+}
+
 func TestConnPoolQueryConcurrentLoad(t *testing.T) {
 	t.Parallel()
 
@@ -977,6 +1922,54 @@ func TestConnectEagerlyReachesMinPoolSize(t *testing.T) {
 
 }
 
+func TestPoolSendBatchCanceledContextDestroysConn(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	poisonedPID := c.Conn().PgConn().PID()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	batch.Queue("select pg_sleep(5)")
+	batch.Queue("select 3")
+
+	br := c.SendBatch(ctx, batch)
+	_, err = br.Exec()
+	require.NoError(t, err)
+
+	// Cancel while the remaining results are pending.
+	cancel()
+	_, err = br.Exec()
+	require.Error(t, err)
+	require.Error(t, br.Close())
+	c.Release()
+	waitForReleaseToComplete()
+
+	assert.EqualValues(t, 1, pool.Stat().BrokenConnDestroyCount())
+
+	c, err = pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer c.Release()
+	assert.NotEqual(t, poisonedPID, c.Conn().PgConn().PID())
+
+	var n int32
+	err = c.QueryRow(context.Background(), "select 42").Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, n)
+}
+
 func TestPoolSendBatchBatchCloseTwice(t *testing.T) {
 	t.Parallel()
 
@@ -1028,3 +2021,95 @@ func TestPoolSendBatchBatchCloseTwice(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestPoolCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	// Nothing listens on port 1 so every connection attempt fails quickly.
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 connect_timeout=5")
+	require.NoError(t, err)
+	config.CircuitBreaker = pgxpool.CircuitBreaker{FailureThreshold: 2, CooldownDuration: 200 * time.Millisecond}
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err = db.Acquire(context.Background())
+		require.Error(t, err)
+		require.NotErrorIs(t, err, pgxpool.ErrCircuitOpen)
+	}
+
+	_, err = db.Acquire(context.Background())
+	require.ErrorIs(t, err, pgxpool.ErrCircuitOpen)
+
+	time.Sleep(250 * time.Millisecond)
+
+	// A trial whose context is done does not open the circuit again.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = db.Acquire(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The trial connect after the cooldown fails and opens the circuit again.
+	_, err = db.Acquire(context.Background())
+	require.Error(t, err)
+	require.NotErrorIs(t, err, pgxpool.ErrCircuitOpen)
+
+	_, err = db.Acquire(context.Background())
+	require.ErrorIs(t, err, pgxpool.ErrCircuitOpen)
+}
+
+func TestPoolCircuitBreakerTrialServedByIdleConnKeepsCircuitHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.CircuitBreaker = pgxpool.CircuitBreaker{FailureThreshold: 1, CooldownDuration: 100 * time.Millisecond}
+
+	var failConnects int32
+	dialFunc := config.ConnConfig.DialFunc
+	config.ConnConfig.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if atomic.LoadInt32(&failConnects) != 0 {
+			return nil, errors.New("connect disabled")
+		}
+		return dialFunc(ctx, network, addr)
+	}
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	idle, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// A failed connect opens the circuit.
+	atomic.StoreInt32(&failConnects, 1)
+	_, err = db.Acquire(context.Background())
+	require.ErrorContains(t, err, "connect disabled")
+	idle.Release()
+	waitForReleaseToComplete()
+
+	_, err = db.Acquire(context.Background())
+	require.ErrorIs(t, err, pgxpool.ErrCircuitOpen)
+
+	time.Sleep(150 * time.Millisecond)
+
+	// The trial is served by the idle connection. That does not prove connects work so the circuit is not closed but
+	// the next Acquire is another trial.
+	c, err := db.Acquire(context.Background())
+	require.NoError(t, err)
+	c.Release()
+	waitForReleaseToComplete()
+
+	c, err = db.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// With the idle connection acquired the next trial must connect, which fails and opens the circuit again.
+	_, err = db.Acquire(context.Background())
+	require.ErrorContains(t, err, "connect disabled")
+
+	_, err = db.Acquire(context.Background())
+	require.ErrorIs(t, err, pgxpool.ErrCircuitOpen)
+	c.Release()
+}
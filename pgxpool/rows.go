@@ -1,8 +1,13 @@
 package pgxpool
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type errRows struct {
@@ -26,9 +31,10 @@ type errRow struct {
 func (e errRow) Scan(dest ...any) error { return e.err }
 
 type poolRows struct {
-	r   pgx.Rows
-	c   *Conn
-	err error
+	r      pgx.Rows
+	c      *Conn
+	err    error
+	cancel context.CancelFunc // cancels the context with the pool's DefaultQueryTimeout
 }
 
 func (rows *poolRows) Close() {
@@ -37,6 +43,10 @@ func (rows *poolRows) Close() {
 		rows.c.Release()
 		rows.c = nil
 	}
+	if rows.cancel != nil {
+		rows.cancel()
+		rows.cancel = nil
+	}
 }
 
 func (rows *poolRows) Err() error {
@@ -91,9 +101,10 @@ func (rows *poolRows) Conn() *pgx.Conn {
 }
 
 type poolRow struct {
-	r   pgx.Row
-	c   *Conn
-	err error
+	r      pgx.Row
+	c      *Conn
+	err    error
+	cancel context.CancelFunc // cancels the context with the pool's DefaultQueryTimeout
 }
 
 func (row *poolRow) Scan(dest ...any) error {
@@ -105,5 +116,74 @@ func (row *poolRow) Scan(dest ...any) error {
 	if row.c != nil {
 		row.c.Release()
 	}
+	if row.cancel != nil {
+		row.cancel()
+	}
 	return err
 }
+
+// trackedRows wraps pgx.Rows to report the query to OnSlowQuery and QueryLogger when it completes.
+type trackedRows struct {
+	pgx.Rows
+	ctx       context.Context
+	p         *Pool
+	sql       string
+	args      []any
+	startTime time.Time
+	checked   bool
+}
+
+func (rows *trackedRows) check() {
+	if !rows.checked {
+		rows.checked = true
+		duration := time.Since(rows.startTime)
+		rows.p.checkSlowQuery(rows.ctx, rows.sql, duration, rows.args)
+		rows.p.logQuery(rows.ctx, rows.sql, duration, rows.args, rows.Rows.Err())
+	}
+}
+
+func (rows *trackedRows) Close() {
+	rows.Rows.Close()
+	rows.check()
+}
+
+func (rows *trackedRows) Next() bool {
+	n := rows.Rows.Next()
+	if !n {
+		rows.check()
+	}
+	return n
+}
+
+// trackedRow implements pgx.Row on top of trackedRows with the same behavior as the pgx.Row returned by
+// pgx.Conn.QueryRow.
+type trackedRow struct {
+	rows pgx.Rows
+}
+
+func (row trackedRow) Scan(dest ...any) error {
+	rows := row.rows
+
+	if rows.Err() != nil {
+		rows.Close()
+		return rows.Err()
+	}
+
+	for _, d := range dest {
+		if _, ok := d.(*pgtype.DriverBytes); ok {
+			rows.Close()
+			return errors.New("cannot scan into *pgtype.DriverBytes from QueryRow")
+		}
+	}
+
+	if !rows.Next() {
+		if rows.Err() == nil {
+			return pgx.ErrNoRows
+		}
+		return rows.Err()
+	}
+
+	rows.Scan(dest...)
+	rows.Close()
+	return rows.Err()
+}
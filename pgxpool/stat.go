@@ -8,10 +8,12 @@ import (
 
 // Stat is a snapshot of Pool statistics.
 type Stat struct {
-	s                    *puddle.Stat
-	newConnsCount        int64
-	lifetimeDestroyCount int64
-	idleDestroyCount     int64
+	s                       *puddle.Stat
+	newConnsCount           int64
+	lifetimeDestroyCount    int64
+	idleDestroyCount        int64
+	healthCheckDestroyCount int64
+	brokenConnDestroyCount  int64
 }
 
 // AcquireCount returns the cumulative count of successful acquires from the pool.
@@ -45,6 +47,9 @@ func (s *Stat) ConstructingConns() int32 {
 // EmptyAcquireCount returns the cumulative count of successful acquires from the pool
 // that waited for a resource to be released or constructed because the pool was
 // empty.
+//
+// AcquireCount - EmptyAcquireCount is the number of acquires that were served
+// immediately by an idle connection.
 func (s *Stat) EmptyAcquireCount() int64 {
 	return s.s.EmptyAcquireCount()
 }
@@ -82,3 +87,39 @@ func (s *Stat) MaxLifetimeDestroyCount() int64 {
 func (s *Stat) MaxIdleDestroyCount() int64 {
 	return s.idleDestroyCount
 }
+
+// HealthCheckDestroyCount returns the cumulative count of idle connections
+// destroyed because they failed the liveness check performed when they were
+// acquired.
+func (s *Stat) HealthCheckDestroyCount() int64 {
+	return s.healthCheckDestroyCount
+}
+
+// BrokenConnDestroyCount returns the cumulative count of connections destroyed
+// when they were released because they were closed, busy, or in a transaction,
+// e.g. due to a network error or a canceled query.
+func (s *Stat) BrokenConnDestroyCount() int64 {
+	return s.brokenConnDestroyCount
+}
+
+// HostStat is a snapshot of the connections in a Pool to a single host.
+type HostStat struct {
+	acquiredConns int32
+	totalConns    int32
+}
+
+// AcquiredConns returns the number of currently acquired connections to the host.
+func (s HostStat) AcquiredConns() int32 {
+	return s.acquiredConns
+}
+
+// IdleConns returns the number of currently idle connections to the host.
+func (s HostStat) IdleConns() int32 {
+	return s.totalConns - s.acquiredConns
+}
+
+// TotalConns returns the total number of established connections to the host. A host remains present with a count of
+// zero after all of its connections have been closed.
+func (s HostStat) TotalConns() int32 {
+	return s.totalConns
+}
@@ -78,3 +78,29 @@ func TestTxCopyFrom(t *testing.T) {
 
 	testCopyFrom(t, tx)
 }
+
+func TestPoolAcquireSnapshot(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	tx, err := pool.AcquireSnapshot(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 1, pool.Stat().AcquiredConns())
+
+	var isoLevel, readOnly, deferrable string
+	err = tx.QueryRow(context.Background(), "select current_setting('transaction_isolation'), current_setting('transaction_read_only'), current_setting('transaction_deferrable')").Scan(&isoLevel, &readOnly, &deferrable)
+	require.NoError(t, err)
+	require.Equal(t, "serializable", isoLevel)
+	require.Equal(t, "on", readOnly)
+	require.Equal(t, "on", deferrable)
+
+	_, err = tx.Exec(context.Background(), "create temporary table t(id int)")
+	require.Error(t, err)
+
+	err = tx.Rollback(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
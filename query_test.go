@@ -14,6 +14,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/assert"
@@ -190,6 +191,187 @@ func TestConnQueryValuesWhenUnableToDecode(t *testing.T) {
 	require.Equal(t, "({1},)", values[0])
 }
 
+func TestConnQueryCacheResult(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.ResultCacheCapacity = 2
+	config.ResultCacheTTL = time.Hour
+
+	ctx := context.Background()
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table result_cache (id int4 primary key, name text not null);
+insert into result_cache values (1, 'a'), (2, 'b');`)
+
+	queryName := func(id int32) string {
+		var name string
+		err := conn.QueryRow(ctx, "select name from result_cache where id = $1", pgx.QueryCacheResult{}, id).Scan(&name)
+		require.NoError(t, err)
+		return name
+	}
+
+	require.Equal(t, "a", queryName(1))
+	mustExec(t, conn, "update result_cache set name = 'c' where id = 1")
+	require.Equal(t, "a", queryName(1))
+
+	// Queries without QueryCacheResult are not cached.
+	var name string
+	err := conn.QueryRow(ctx, "select name from result_cache where id = $1", int32(1)).Scan(&name)
+	require.NoError(t, err)
+	require.Equal(t, "c", name)
+
+	conn.InvalidateCache("select name from result_cache where id = $1", int32(1))
+	require.Equal(t, "c", queryName(1))
+
+	rows, _ := conn.Query(ctx, "select id, name from result_cache order by id", pgx.QueryCacheResult{})
+	values, err := pgx.CollectRows(rows, pgx.RowToMap)
+	require.NoError(t, err)
+	require.Equal(t, "select 2", rows.CommandTag().String())
+
+	rows, _ = conn.Query(ctx, "select id, name from result_cache order by id", pgx.QueryCacheResult{})
+	cachedValues, err := pgx.CollectRows(rows, pgx.RowToMap)
+	require.NoError(t, err)
+	require.Equal(t, values, cachedValues)
+	require.Equal(t, "select 2", rows.CommandTag().String())
+
+	// The unread rows of a partially read result are read when the rows are closed so the entire result is cached.
+	rows, _ = conn.Query(ctx, "select name from result_cache where id = any($1) order by id", pgx.QueryCacheResult{}, []int32{1, 2})
+	require.True(t, rows.Next())
+	rows.Close()
+	require.NoError(t, rows.Err())
+	mustExec(t, conn, "update result_cache set name = 'd' where id = 1")
+	rows, _ = conn.Query(ctx, "select name from result_cache where id = any($1) order by id", pgx.QueryCacheResult{}, []int32{1, 2})
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b"}, names)
+	require.Equal(t, "select 2", rows.CommandTag().String())
+
+	// The cache holds at most 2 results so the least recently used result for id 1 has been evicted.
+	require.Equal(t, "d", queryName(1))
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnQueryCacheResultTTL(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.ResultCacheCapacity = 10
+	config.ResultCacheTTL = 50 * time.Millisecond
+
+	ctx := context.Background()
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	var first, second, third time.Time
+	err := conn.QueryRow(ctx, "select clock_timestamp()", pgx.QueryCacheResult{}).Scan(&first)
+	require.NoError(t, err)
+	err = conn.QueryRow(ctx, "select clock_timestamp()", pgx.QueryCacheResult{}).Scan(&second)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	time.Sleep(100 * time.Millisecond)
+
+	err = conn.QueryRow(ctx, "select clock_timestamp()", pgx.QueryCacheResult{}).Scan(&third)
+	require.NoError(t, err)
+	require.True(t, third.After(first))
+}
+
+func TestConnQueryMaxRows(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, "select n from generate_series(1, 1000) n", pgx.QueryMaxRows(10))
+		_, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.ErrorIs(t, err, pgx.ErrTooManyRows)
+
+		rows, _ = conn.Query(ctx, "select n from generate_series(1, $1::int4) n", pgx.QueryMaxRows(10), 10)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Len(t, numbers, 10)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryResultFields(t *testing.T) {
+	t.Parallel()
+
+	// QueryResultFields has no effect with the simple protocol.
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		fields := pgx.QueryResultFields{
+			{DataTypeOID: pgtype.Int4OID, Format: pgx.BinaryFormatCode},
+			{DataTypeOID: pgtype.TextOID, Format: pgx.TextFormatCode},
+		}
+
+		var n int32
+		var s string
+		err := conn.QueryRow(ctx, "select $1::int4 + 1, 'foo'::text", fields, 41).Scan(&n, &s)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+		require.Equal(t, "foo", s)
+
+		err = conn.QueryRow(ctx, "select 'foo'::text, 1::int4", fields).Scan(&s, &n)
+		require.EqualError(t, err, "expected result field 0 to have type OID 23, got 25")
+
+		err = conn.QueryRow(ctx, "select 1::int4", fields).Scan(&n)
+		require.EqualError(t, err, "expected 2 result fields, got 1")
+
+		// A query error is not hidden by the field check.
+		err = conn.QueryRow(ctx, "select 1/0, 'foo'::text", fields).Scan(&n, &s)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryResultFieldsSkipsResultDescription(t *testing.T) {
+	t.Parallel()
+
+	// QueryExecModeExec describes the result to check it against the supplied fields.
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		fields := pgx.QueryResultFields{
+			{DataTypeOID: pgtype.Int4OID, Format: pgx.BinaryFormatCode},
+		}
+
+		sql := "select $1::int4 + 1"
+
+		// Cache the statement description in the modes that describe the statement.
+		var n int32
+		err := conn.QueryRow(ctx, sql, fields, 1).Scan(&n)
+		require.NoError(t, err)
+
+		var trace bytes.Buffer
+		conn.PgConn().Frontend().Trace(&trace, pgproto3.TracerOptions{SuppressTimestamps: true})
+		err = conn.QueryRow(ctx, sql, fields, 41).Scan(&n)
+		conn.PgConn().Frontend().Untrace()
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+
+		require.Contains(t, trace.String(), "DataRow")
+		require.NotContains(t, trace.String(), "Describe")
+		require.NotContains(t, trace.String(), "RowDescription")
+
+		ensureConnValid(t, conn)
+	})
+}
+
 func TestConnQueryValuesWithUnregisteredOID(t *testing.T) {
 	t.Parallel()
 
@@ -366,6 +548,9 @@ func TestConnQueryCloseEarly(t *testing.T) {
 	}
 
 	rows.Close()
+	require.NoError(t, rows.Err())
+	// The remaining rows were read and discarded.
+	require.EqualValues(t, 10, rows.CommandTag().RowsAffected())
 
 	ensureConnValid(t, conn)
 }
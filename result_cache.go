@@ -0,0 +1,141 @@
+package pgx
+
+import (
+	"container/list"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/internal/anynil"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// QueryCacheResult marks a query as eligible for the result cache when passed as one of the first arguments to Query.
+// The result cache must be enabled with ConnConfig.ResultCacheCapacity. Results are cached per connection and keyed by
+// the SQL and the encoded arguments. A cached result is returned without contacting the server until it is older than
+// ConnConfig.ResultCacheTTL or it is removed with InvalidateCache. The entire result is buffered in memory so it should
+// only be used for small results of rarely changing data. Rows that are not read, such as all but the first row of
+// QueryRow, are still read from the server and stored when the rows are closed. A result is only stored if the query
+// succeeds.
+//
+// The values returned by Rows.RawValues for a cached result are shared with the cache and must not be modified.
+//
+// QueryCacheResult is ignored when combined with QueryResultFormats, QueryResultFormatsByOID, or QueryResultFields.
+type QueryCacheResult struct{}
+
+type cachedResult struct {
+	key        string
+	fields     []pgconn.FieldDescription
+	rows       [][][]byte
+	commandTag pgconn.CommandTag
+	expiresAt  time.Time
+}
+
+// resultCache is a least recently used cache of query results with a time to live.
+type resultCache struct {
+	cap int
+	ttl time.Duration
+	m   map[string]*list.Element
+	l   *list.List
+}
+
+func newResultCache(cap int, ttl time.Duration) *resultCache {
+	return &resultCache{
+		cap: cap,
+		ttl: ttl,
+		m:   make(map[string]*list.Element),
+		l:   list.New(),
+	}
+}
+
+// get returns the result for key if it is present and has not expired.
+func (c *resultCache) get(key string) *cachedResult {
+	el, ok := c.m[key]
+	if !ok {
+		return nil
+	}
+
+	result := el.Value.(*cachedResult)
+	if !time.Now().Before(result.expiresAt) {
+		c.l.Remove(el)
+		delete(c.m, key)
+		return nil
+	}
+
+	c.l.MoveToFront(el)
+	return result
+}
+
+// put stores result replacing any existing result with the same key.
+func (c *resultCache) put(result *cachedResult) {
+	result.expiresAt = time.Now().Add(c.ttl)
+
+	if el, ok := c.m[result.key]; ok {
+		el.Value = result
+		c.l.MoveToFront(el)
+		return
+	}
+
+	if c.l.Len() == c.cap {
+		oldest := c.l.Back()
+		c.l.Remove(oldest)
+		delete(c.m, oldest.Value.(*cachedResult).key)
+	}
+
+	c.m[result.key] = c.l.PushFront(result)
+}
+
+func (c *resultCache) invalidate(key string) {
+	if el, ok := c.m[key]; ok {
+		c.l.Remove(el)
+		delete(c.m, key)
+	}
+}
+
+// recordRow appends a copy of values to the result.
+func (r *cachedResult) recordRow(values [][]byte) {
+	row := make([][]byte, len(values))
+	for i, v := range values {
+		if v != nil {
+			row[i] = append(make([]byte, 0, len(v)), v...)
+		}
+	}
+	r.rows = append(r.rows, row)
+}
+
+// resultCacheKey returns the key for sql executed with args. ok is false if args cannot be encoded.
+func (c *Conn) resultCacheKey(sql string, args []any) (key string, ok bool) {
+	var eqb ExtendedQueryBuilder
+	if err := eqb.Build(c.typeMap, nil, args); err != nil {
+		return "", false
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sql)
+	for i, v := range eqb.ParamValues {
+		buf := make([]byte, 6)
+		binary.BigEndian.PutUint16(buf, uint16(eqb.ParamFormats[i]))
+		if v == nil {
+			binary.BigEndian.PutUint32(buf[2:], uint32(0xFFFFFFFF))
+		} else {
+			binary.BigEndian.PutUint32(buf[2:], uint32(len(v)))
+		}
+		sb.Write(buf)
+		sb.Write(v)
+	}
+
+	return sb.String(), true
+}
+
+// InvalidateCache removes the cached result of sql executed with args from the result cache. It has no effect if the
+// result is not cached.
+func (c *Conn) InvalidateCache(sql string, args ...any) {
+	if c.resultCache == nil {
+		return
+	}
+
+	anynil.NormalizeSlice(args)
+	if key, ok := c.resultCacheKey(sql, args); ok {
+		c.resultCache.invalidate(key)
+	}
+}
@@ -24,8 +24,9 @@ import (
 // the Rows interface is partially excluded from semantic version requirements.
 // Methods will not be removed or changed, but new methods may be added.
 type Rows interface {
-	// Close closes the rows, making the connection ready for use again. It is safe
-	// to call Close after rows is already closed.
+	// Close closes the rows, making the connection ready for use again. Any remaining rows are read from the
+	// connection and discarded without being decoded, so closing early is the efficient way to skip the rest of a
+	// result. It is safe to call Close after rows is already closed.
 	Close()
 
 	// Err returns any error that occurred while reading.
@@ -133,9 +134,20 @@ type baseRows struct {
 	sql         string
 	args        []any
 	rowCount    int
+
+	maxRows int // maximum number of rows allowed by QueryMaxRows
+
+	wrapBatchItemError func(err error) error // wraps errors returned by the server for a batch query in *BatchItemError
+
+	cacheHit        *cachedResult // result read from the result cache instead of resultReader
+	cacheRecord     *cachedResult // result being recorded for the result cache
+	cacheRecordDone bool          // all rows have been recorded
 }
 
 func (rows *baseRows) FieldDescriptions() []pgconn.FieldDescription {
+	if rows.cacheHit != nil {
+		return rows.cacheHit.fields
+	}
 	return rows.resultReader.FieldDescriptions()
 }
 
@@ -146,6 +158,19 @@ func (rows *baseRows) Close() {
 
 	rows.closed = true
 
+	// Rows that were not read, such as all but the first row of QueryRow, are still recorded for the result cache.
+	if rows.cacheRecord != nil && !rows.cacheRecordDone && rows.err == nil && rows.resultReader != nil {
+		for rows.resultReader.NextRow() {
+			rows.rowCount++
+			if rows.maxRows > 0 && rows.rowCount > rows.maxRows {
+				rows.cacheRecord = nil
+				break
+			}
+			rows.cacheRecord.recordRow(rows.resultReader.Values())
+		}
+		rows.cacheRecordDone = rows.cacheRecord != nil
+	}
+
 	if rows.resultReader != nil {
 		var closeErr error
 		rows.commandTag, closeErr = rows.resultReader.Close()
@@ -161,6 +186,12 @@ func (rows *baseRows) Close() {
 		}
 	}
 
+	if rows.cacheRecord != nil && rows.cacheRecordDone && rows.err == nil && rows.conn.resultCache != nil {
+		rows.cacheRecord.fields = append([]pgconn.FieldDescription(nil), rows.resultReader.FieldDescriptions()...)
+		rows.cacheRecord.commandTag = pgconn.NewCommandTag(rows.commandTag.String())
+		rows.conn.resultCache.put(rows.cacheRecord)
+	}
+
 	if rows.err != nil && rows.conn != nil && rows.sql != "" {
 		if stmtcache.IsStatementInvalid(rows.err) {
 			if sc := rows.conn.statementCache; sc != nil {
@@ -173,6 +204,10 @@ func (rows *baseRows) Close() {
 		}
 	}
 
+	if rows.err != nil && rows.wrapBatchItemError != nil {
+		rows.err = rows.wrapBatchItemError(rows.err)
+	}
+
 	if rows.batchTracer != nil {
 		rows.batchTracer.TraceBatchQuery(rows.ctx, rows.conn, TraceBatchQueryData{SQL: rows.sql, Args: rows.args, CommandTag: rows.commandTag, Err: rows.err})
 	} else if rows.queryTracer != nil {
@@ -204,11 +239,33 @@ func (rows *baseRows) Next() bool {
 		return false
 	}
 
+	if rows.cacheHit != nil {
+		if rows.rowCount < len(rows.cacheHit.rows) {
+			if rows.maxRows > 0 && rows.rowCount >= rows.maxRows {
+				rows.fatal(fmt.Errorf("%w: limit is %d", ErrTooManyRows, rows.maxRows))
+				return false
+			}
+			rows.values = rows.cacheHit.rows[rows.rowCount]
+			rows.rowCount++
+			return true
+		}
+		rows.Close()
+		return false
+	}
+
 	if rows.resultReader.NextRow() {
 		rows.rowCount++
+		if rows.maxRows > 0 && rows.rowCount > rows.maxRows {
+			rows.fatal(fmt.Errorf("%w: limit is %d", ErrTooManyRows, rows.maxRows))
+			return false
+		}
 		rows.values = rows.resultReader.Values()
+		if rows.cacheRecord != nil {
+			rows.cacheRecord.recordRow(rows.values)
+		}
 		return true
 	} else {
+		rows.cacheRecordDone = true
 		rows.Close()
 		return false
 	}
@@ -272,21 +329,33 @@ func (rows *baseRows) Values() ([]any, error) {
 		return nil, errors.New("rows is closed")
 	}
 
-	values := make([]any, 0, len(rows.FieldDescriptions()))
+	values, err := decodeRowValues(rows.typeMap, rows.FieldDescriptions(), rows.values)
+	if err != nil {
+		rows.fatal(err)
+		return nil, rows.Err()
+	}
+
+	return values, rows.Err()
+}
+
+// decodeRowValues decodes the raw values of a row into Go values. Values of unknown types are returned as a string
+// for the text format or as a copy of the []byte for the binary format.
+func decodeRowValues(typeMap *pgtype.Map, fieldDescriptions []pgconn.FieldDescription, rawValues [][]byte) ([]any, error) {
+	values := make([]any, 0, len(fieldDescriptions))
 
-	for i := range rows.FieldDescriptions() {
-		buf := rows.values[i]
-		fd := &rows.FieldDescriptions()[i]
+	for i := range fieldDescriptions {
+		buf := rawValues[i]
+		fd := &fieldDescriptions[i]
 
 		if buf == nil {
 			values = append(values, nil)
 			continue
 		}
 
-		if dt, ok := rows.typeMap.TypeForOID(fd.DataTypeOID); ok {
-			value, err := dt.Codec.DecodeValue(rows.typeMap, fd.DataTypeOID, fd.Format, buf)
+		if dt, ok := typeMap.TypeForOID(fd.DataTypeOID); ok {
+			value, err := dt.Codec.DecodeValue(typeMap, fd.DataTypeOID, fd.Format, buf)
 			if err != nil {
-				rows.fatal(err)
+				return nil, err
 			}
 			values = append(values, value)
 		} else {
@@ -298,16 +367,12 @@ func (rows *baseRows) Values() ([]any, error) {
 				copy(newBuf, buf)
 				values = append(values, newBuf)
 			default:
-				rows.fatal(errors.New("Unknown format code"))
+				return nil, errors.New("Unknown format code")
 			}
 		}
-
-		if rows.Err() != nil {
-			return nil, rows.Err()
-		}
 	}
 
-	return values, rows.Err()
+	return values, nil
 }
 
 func (rows *baseRows) RawValues() [][]byte {
@@ -449,6 +514,22 @@ func CollectOneRow[T any](rows Rows, fn RowToFunc[T]) (T, error) {
 	return value, rows.Err()
 }
 
+// CollectColumn scans the only column of each row in rows into a slice of T. It returns an error if rows does not have
+// exactly one column.
+func CollectColumn[T any](rows Rows) ([]T, error) {
+	defer rows.Close()
+
+	if n := len(rows.FieldDescriptions()); n != 1 {
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("CollectColumn requires exactly 1 column, got %d", n)
+	}
+
+	return CollectRows(rows, RowTo[T])
+}
+
 // RowTo returns a T scanned from row.
 func RowTo[T any](row CollectableRow) (T, error) {
 	var value T
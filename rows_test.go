@@ -177,6 +177,33 @@ func ExampleCollectRows() {
 	// [1 2 3 4 5]
 }
 
+func TestCollectColumn(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select n from generate_series(1, 5) n`)
+		numbers, err := pgx.CollectColumn[int32](rows)
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 2, 3, 4, 5}, numbers)
+
+		rows, _ = conn.Query(ctx, `select n, n from generate_series(1, 5) n`)
+		_, err = pgx.CollectColumn[int32](rows)
+		require.ErrorContains(t, err, "exactly 1 column, got 2")
+
+		rows, _ = conn.Query(ctx, `select 1 from missing_table`)
+		_, err = pgx.CollectColumn[int32](rows)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+
+		batch := &pgx.Batch{}
+		batch.Queue(`select n::text from generate_series(1, 3) n`)
+		br := conn.SendBatch(ctx, batch)
+		rows, _ = br.Query()
+		strs, err := pgx.CollectColumn[string](rows)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3"}, strs)
+		require.NoError(t, br.Close())
+	})
+}
+
 func TestCollectOneRow(t *testing.T) {
 	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
 		rows, _ := conn.Query(ctx, `select 42`)
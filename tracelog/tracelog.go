@@ -117,11 +117,26 @@ func logQueryArgs(args []any) []any {
 	return logArgs
 }
 
-// TraceLog implements pgx.QueryTracer, pgx.BatchTracer, pgx.ConnectTracer, and pgx.CopyFromTracer. All fields are
-// required.
+// TraceLog implements pgx.QueryTracer, pgx.BatchTracer, pgx.ConnectTracer, and pgx.CopyFromTracer. Logger and
+// LogLevel are required. To log the queries of all connections in a pgxpool.Pool set it as the Tracer of the pool's
+// ConnConfig.
 type TraceLog struct {
 	Logger   Logger
 	LogLevel LogLevel
+
+	// RedactArgs causes only the number of query arguments to be logged, as argsCount, instead of their values as args.
+	// Use it when arguments may contain sensitive data.
+	RedactArgs bool
+}
+
+// withArgs adds args to the log data according to RedactArgs.
+func (tl *TraceLog) withArgs(data map[string]any, args []any) map[string]any {
+	if tl.RedactArgs {
+		data["argsCount"] = len(args)
+	} else {
+		data["args"] = logQueryArgs(args)
+	}
+	return data
 }
 
 type ctxKey int
@@ -157,13 +172,13 @@ func (tl *TraceLog) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.
 
 	if data.Err != nil {
 		if tl.shouldLog(LogLevelError) {
-			tl.log(ctx, conn, LogLevelError, "Query", map[string]any{"sql": queryData.sql, "args": logQueryArgs(queryData.args), "err": data.Err, "time": interval})
+			tl.log(ctx, conn, LogLevelError, "Query", tl.withArgs(map[string]any{"sql": queryData.sql, "err": data.Err, "time": interval}, queryData.args))
 		}
 		return
 	}
 
 	if tl.shouldLog(LogLevelInfo) {
-		tl.log(ctx, conn, LogLevelInfo, "Query", map[string]any{"sql": queryData.sql, "args": logQueryArgs(queryData.args), "time": interval, "commandTag": data.CommandTag.String()})
+		tl.log(ctx, conn, LogLevelInfo, "Query", tl.withArgs(map[string]any{"sql": queryData.sql, "time": interval, "commandTag": data.CommandTag.String()}, queryData.args))
 	}
 }
 
@@ -180,13 +195,13 @@ func (tl *TraceLog) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pg
 func (tl *TraceLog) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
 	if data.Err != nil {
 		if tl.shouldLog(LogLevelError) {
-			tl.log(ctx, conn, LogLevelError, "BatchQuery", map[string]any{"sql": data.SQL, "args": logQueryArgs(data.Args), "err": data.Err})
+			tl.log(ctx, conn, LogLevelError, "BatchQuery", tl.withArgs(map[string]any{"sql": data.SQL, "err": data.Err}, data.Args))
 		}
 		return
 	}
 
 	if tl.shouldLog(LogLevelInfo) {
-		tl.log(ctx, conn, LogLevelInfo, "BatchQuery", map[string]any{"sql": data.SQL, "args": logQueryArgs(data.Args), "commandTag": data.CommandTag.String()})
+		tl.log(ctx, conn, LogLevelInfo, "BatchQuery", tl.withArgs(map[string]any{"sql": data.SQL, "commandTag": data.CommandTag.String()}, data.Args))
 	}
 }
 
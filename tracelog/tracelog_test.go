@@ -7,8 +7,10 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/jackc/pgx/v5/tracelog"
 	"github.com/stretchr/testify/assert"
@@ -155,6 +157,58 @@ func TestLogQuery(t *testing.T) {
 	})
 }
 
+func TestLogPoolQueriesRedactArgs(t *testing.T) {
+	t.Parallel()
+
+	logger := &testLogger{}
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.ConnConfig.Tracer = &tracelog.TraceLog{
+		Logger:     logger,
+		LogLevel:   tracelog.LogLevelInfo,
+		RedactArgs: true,
+	}
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	_, err = pool.Exec(ctx, "select pg_sleep(0.1), $1::text", "secret")
+	require.NoError(t, err)
+
+	rows, err := pool.Query(ctx, "select $1::text, $2::text", "secret", "secret")
+	require.NoError(t, err)
+	rows.Close()
+	require.NoError(t, rows.Err())
+
+	var s string
+	err = pool.QueryRow(ctx, "select $1::text", "secret").Scan(&s)
+	require.NoError(t, err)
+
+	logs := logger.FilterByMsg("Query")
+	require.Len(t, logs, 3)
+	for i, argsCount := range []int{1, 2, 1} {
+		require.Equal(t, argsCount, logs[i].data["argsCount"])
+		require.NotContains(t, logs[i].data, "args")
+	}
+	require.GreaterOrEqual(t, logs[0].data["time"], 100*time.Millisecond)
+
+	batch := &pgx.Batch{}
+	batch.Queue("select $1::text", "secret")
+	err = pool.SendBatch(ctx, batch).Close()
+	require.NoError(t, err)
+
+	logs = logger.FilterByMsg("BatchQuery")
+	require.Len(t, logs, 1)
+	require.Equal(t, 1, logs[0].data["argsCount"])
+	require.NotContains(t, logs[0].data, "args")
+	require.Len(t, logger.FilterByMsg("BatchClose"), 1)
+}
+
 // https://github.com/jackc/pgx/issues/1365
 func TestLogQueryArgsHandlesUTF8(t *testing.T) {
 	t.Parallel()
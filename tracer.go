@@ -25,13 +25,19 @@ type TraceQueryEndData struct {
 	Err        error
 }
 
-// BatchTracer traces SendBatch.
+// BatchTracer traces SendBatch and SendBatchSimple.
 type BatchTracer interface {
 	// TraceBatchStart is called at the beginning of SendBatch calls. The returned context is used for the
-	// rest of the call and will be passed to TraceBatchQuery and TraceBatchEnd.
+	// rest of the call and will be passed to TraceBatchQuery and TraceBatchEnd. The number of queued queries is
+	// data.Batch.Len().
 	TraceBatchStart(ctx context.Context, conn *Conn, data TraceBatchStartData) context.Context
 
+	// TraceBatchQuery is called each time the results of a query are read from the BatchResults, in the order the
+	// queries were queued. It is not called by SendBatchSimple.
 	TraceBatchQuery(ctx context.Context, conn *Conn, data TraceBatchQueryData)
+
+	// TraceBatchEnd is called when the BatchResults are closed or, if the batch could not be sent, before SendBatch
+	// returns.
 	TraceBatchEnd(ctx context.Context, conn *Conn, data TraceBatchEndData)
 }
 
@@ -2,6 +2,7 @@ package pgx_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
@@ -230,6 +231,61 @@ func TestTraceBatchNormal(t *testing.T) {
 	})
 }
 
+func TestTraceBatchCallbackOrder(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var events []string
+		tracer.traceBatchStart = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+			events = append(events, fmt.Sprintf("start %d", data.Batch.Len()))
+			return ctx
+		}
+		tracer.traceBatchQuery = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+			events = append(events, fmt.Sprintf("query %s %v", data.SQL, data.Err))
+		}
+		tracer.traceBatchEnd = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+			events = append(events, fmt.Sprintf("end %v", data.Err))
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue(`select 1`)
+		batch.Queue(`select 2`)
+		batch.Queue(`select 3`)
+
+		br := conn.SendBatch(context.Background(), batch)
+		_, err := br.Exec()
+		require.NoError(t, err)
+		// The remaining queries are read by Close.
+		err = br.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, []string{
+			"start 3",
+			"query select 1 <nil>",
+			"query select 2 <nil>",
+			"query select 3 <nil>",
+			"end <nil>",
+		}, events)
+
+		events = nil
+		batch = &pgx.Batch{}
+		batch.Queue(`select 1`)
+		batch.Queue(`select 2`)
+		err = conn.SendBatchSimple(context.Background(), batch)
+		require.NoError(t, err)
+		require.Equal(t, []string{"start 2", "end <nil>"}, events)
+	})
+}
+
 func TestTraceBatchClose(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -44,6 +45,12 @@ type TxOptions struct {
 	IsoLevel       TxIsoLevel
 	AccessMode     TxAccessMode
 	DeferrableMode TxDeferrableMode
+
+	// DeadlineStatementTimeout causes SendBatch on the transaction to set statement_timeout for the remainder of the
+	// transaction to the time remaining until the context deadline. This enforces the deadline on the server even if the
+	// client is unable to cancel the query. It has no effect if the context does not have a deadline. A query canceled
+	// because of the deadline fails with a *BatchItemError wrapping a *DeadlineStatementTimeoutError.
+	DeadlineStatementTimeout bool
 }
 
 var emptyTxOptions TxOptions
@@ -97,7 +104,7 @@ func (c *Conn) BeginTx(ctx context.Context, txOptions TxOptions) (Tx, error) {
 		return nil, err
 	}
 
-	return &dbTx{conn: c}, nil
+	return &dbTx{conn: c, deadlineStatementTimeout: txOptions.DeadlineStatementTimeout}, nil
 }
 
 // Tx represents a database transaction.
@@ -143,10 +150,11 @@ type Tx interface {
 // All dbTx methods return ErrTxClosed if Commit or Rollback has already been
 // called on the dbTx.
 type dbTx struct {
-	conn         *Conn
-	err          error
-	savepointNum int64
-	closed       bool
+	conn                     *Conn
+	err                      error
+	savepointNum             int64
+	closed                   bool
+	deadlineStatementTimeout bool
 }
 
 // Begin starts a pseudo nested transaction implemented with a savepoint.
@@ -255,7 +263,66 @@ func (tx *dbTx) SendBatch(ctx context.Context, b *Batch) BatchResults {
 		return &batchResults{err: ErrTxClosed}
 	}
 
-	return tx.conn.SendBatch(ctx, b)
+	if !tx.deadlineStatementTimeout {
+		return tx.conn.SendBatch(ctx, b)
+	}
+
+	deadline, err := tx.setDeadlineStatementTimeout(ctx)
+	if err != nil {
+		return &batchResults{err: err}
+	}
+
+	br := tx.conn.SendBatch(ctx, b)
+	switch br := br.(type) {
+	case *batchResults:
+		br.itemErrs.statementTimeoutDeadline = deadline
+	case *pipelineBatchResults:
+		br.itemErrs.statementTimeoutDeadline = deadline
+	}
+
+	return br
+}
+
+// setDeadlineStatementTimeout sets statement_timeout for the rest of the transaction to the time remaining until the
+// ctx deadline. It returns the deadline or the zero time if ctx does not have a deadline.
+func (tx *dbTx) setDeadlineStatementTimeout(ctx context.Context) (time.Time, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	// A statement_timeout of 0 disables the timeout so an expired deadline must be handled here.
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining <= 0 {
+		return time.Time{}, &DeadlineStatementTimeoutError{Deadline: deadline, Err: context.DeadlineExceeded}
+	}
+
+	_, err := tx.conn.Exec(ctx, "set local statement_timeout = "+strconv.FormatInt(remaining, 10), QueryExecModeSimpleProtocol)
+	return deadline, err
+}
+
+// DeadlineStatementTimeoutError is returned when a batch sent on a transaction with TxOptions.DeadlineStatementTimeout
+// fails because the context deadline passed. It is the Err of the *BatchItemError for the query that failed and wraps
+// either the *pgconn.PgError returned when the server canceled the query or the client side timeout error.
+//
+// errors.Is(err, context.DeadlineExceeded) is true for a DeadlineStatementTimeoutError.
+type DeadlineStatementTimeoutError struct {
+	// Deadline is the context deadline statement_timeout was set from.
+	Deadline time.Time
+
+	Err error
+}
+
+func (e *DeadlineStatementTimeoutError) Error() string {
+	return fmt.Sprintf("statement timeout from context deadline %s exceeded: %v", e.Deadline.Format(time.RFC3339Nano), e.Err)
+}
+
+func (e *DeadlineStatementTimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+func (e *DeadlineStatementTimeoutError) Unwrap() error {
+	return e.Err
 }
 
 // LargeObjects returns a LargeObjects instance for the transaction.
@@ -407,6 +474,36 @@ func BeginTxFunc(
 	return beginFuncExec(ctx, tx, fn)
 }
 
+// BeginTxFuncRetry is like BeginTxFunc, but when txOptions.IsoLevel is Serializable or RepeatableRead and the
+// transaction fails with a serialization failure (SQLSTATE 40001) or a deadlock (SQLSTATE 40P01) it is rolled back and
+// fn is called again in a new transaction. fn is called at most maxAttempts times and must be safe to call more than
+// once. The error of the last attempt is returned. Attempts stop when ctx is done.
+func BeginTxFuncRetry(
+	ctx context.Context,
+	db interface {
+		BeginTx(ctx context.Context, txOptions TxOptions) (Tx, error)
+	},
+	txOptions TxOptions,
+	maxAttempts int,
+	fn func(Tx) error,
+) error {
+	retry := txOptions.IsoLevel == Serializable || txOptions.IsoLevel == RepeatableRead
+
+	for attempt := 1; ; attempt++ {
+		err := BeginTxFunc(ctx, db, txOptions, fn)
+		if err == nil || !retry || attempt >= maxAttempts || ctx.Err() != nil || !isSerializationError(err) {
+			return err
+		}
+	}
+}
+
+// isSerializationError returns true if err is a serialization failure or a deadlock. A transaction that failed with
+// either can succeed if it is retried.
+func isSerializationError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
 func beginFuncExec(ctx context.Context, tx Tx, fn func(Tx) error) (err error) {
 	defer func() {
 		rollbackErr := tx.Rollback(ctx)
@@ -354,6 +354,70 @@ func TestBeginFuncRollbackOnError(t *testing.T) {
 	require.EqualValues(t, 0, n)
 }
 
+func TestBeginTxFuncRetry(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	otherConn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, otherConn)
+
+	pgxtest.SkipCockroachDB(t, conn, "Server serialization failures differ")
+
+	mustExec(t, conn, "drop table if exists begin_tx_func_retry")
+	defer mustExec(t, conn, "drop table if exists begin_tx_func_retry")
+	mustExec(t, conn, "create table begin_tx_func_retry(id int primary key, n int not null)")
+
+	// A concurrent update between reading and updating the row causes a serialization failure.
+	runTx := func(txOptions pgx.TxOptions, maxAttempts int, conflictingAttempts int) (int, error) {
+		mustExec(t, conn, "truncate begin_tx_func_retry; insert into begin_tx_func_retry(id, n) values (1, 0)")
+
+		attempts := 0
+		err := pgx.BeginTxFuncRetry(context.Background(), conn, txOptions, maxAttempts, func(tx pgx.Tx) error {
+			attempts++
+
+			var n int32
+			err := tx.QueryRow(context.Background(), "select n from begin_tx_func_retry where id = 1").Scan(&n)
+			if err != nil {
+				return err
+			}
+
+			if attempts <= conflictingAttempts {
+				_, err = otherConn.Exec(context.Background(), "update begin_tx_func_retry set n = n + 100 where id = 1")
+				require.NoError(t, err)
+			}
+
+			_, err = tx.Exec(context.Background(), "update begin_tx_func_retry set n = n + 1 where id = 1")
+			return err
+		})
+		return attempts, err
+	}
+
+	for _, isoLevel := range []pgx.TxIsoLevel{pgx.Serializable, pgx.RepeatableRead} {
+		attempts, err := runTx(pgx.TxOptions{IsoLevel: isoLevel}, 3, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+
+		var n int32
+		err = conn.QueryRow(context.Background(), "select n from begin_tx_func_retry where id = 1").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 201, n)
+
+		attempts, err = runTx(pgx.TxOptions{IsoLevel: isoLevel}, 2, 2)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "40001", pgErr.Code)
+		require.Equal(t, 2, attempts)
+	}
+
+	// Read committed transactions are not retried. The update waits for and then applies on top of the concurrent
+	// update instead of failing.
+	attempts, err := runTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, 3, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+}
+
 func TestBeginReadOnly(t *testing.T) {
 	t.Parallel()
 
@@ -620,3 +684,71 @@ func TestTxSendBatchClosed(t *testing.T) {
 	_, err = br.Query()
 	require.Error(t, err)
 }
+
+func TestTxSendBatchDeadlineStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	db := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, db)
+
+	tx, err := db.BeginTx(context.Background(), pgx.TxOptions{DeadlineStatementTimeout: true})
+	require.NoError(t, err)
+	defer tx.Rollback(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("show statement_timeout")
+
+	br := tx.SendBatch(ctx, batch)
+
+	var s string
+	err = br.QueryRow().Scan(&s)
+	require.NoError(t, err)
+	require.NotEqual(t, "0", s)
+
+	err = br.Close()
+	require.NoError(t, err)
+}
+
+func TestTxSendBatchDeadlineStatementTimeoutExceeded(t *testing.T) {
+	t.Parallel()
+
+	db := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, db)
+
+	tx, err := db.BeginTx(context.Background(), pgx.TxOptions{DeadlineStatementTimeout: true})
+	require.NoError(t, err)
+	defer tx.Rollback(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	batch.Queue("select pg_sleep(5)")
+	batch.Queue("select 2")
+
+	br := tx.SendBatch(ctx, batch)
+
+	_, err = br.Exec()
+	require.NoError(t, err)
+
+	_, err = br.Exec()
+	require.Error(t, err)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var itemErr *pgx.BatchItemError
+	require.ErrorAs(t, err, &itemErr)
+	require.Equal(t, 1, itemErr.Index)
+	require.Equal(t, "select pg_sleep(5)", itemErr.SQL)
+
+	var timeoutErr *pgx.DeadlineStatementTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.True(t, timeoutErr.Deadline.Equal(deadline))
+	require.Contains(t, err.Error(), "statement timeout from context deadline")
+
+	br.Close()
+}